@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Schedule time zones must resolve on hosts without a zoneinfo database.
+
+	"Assignment3ADP/internal/exchange"
 	"Assignment3ADP/internal/handlers"
+	"Assignment3ADP/internal/notify"
 	"Assignment3ADP/internal/repository"
 	"Assignment3ADP/internal/service"
+	"Assignment3ADP/internal/vin"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -49,22 +60,76 @@ func main() {
 		log.Printf("Warning: Database ping failed: %v. The app may fail on DB operations.", err)
 	}
 
+	rates, err := exchange.NewProvider(exchange.Config{
+		Provider:  getEnv("EXCHANGE_PROVIDER", "nbk"),
+		NBKURL:    getEnv("EXCHANGE_NBK_URL", exchange.DefaultNBKURL),
+		RatesFile: getEnv("EXCHANGE_RATES_FILE", ""),
+	})
+	if err != nil {
+		log.Fatal("Failed to configure exchange-rate provider:", err)
+	}
+
 	repo := repository.NewPostgresRepo(db)
-	adminService := service.NewAdminService(repo)
+	adminService := service.NewAdminService(repo, rates)
+	if path := os.Getenv("VIN_WMI_TABLE"); path != "" {
+		table, err := vin.LoadTable(path)
+		if err != nil {
+			log.Fatalf("Failed to load VIN lookup table %s: %v", path, err)
+		}
+		adminService.VINDecoder = vin.NewDecoder(table)
+		log.Printf("Using VIN lookup table from %s", path)
+	}
+	notifier, err := notify.NewNotifier(notify.Config{
+		Provider:     getEnv("NOTIFY_PROVIDER", "log"),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvInt("SMTP_PORT", 25),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		From:         getEnv("SMTP_FROM", ""),
+	})
+	if err != nil {
+		log.Fatal("Failed to configure notifier:", err)
+	}
+	adminService.Notifier = notifier
+	if v := os.Getenv("PRICE_DROP_ALERT_PERCENT"); v != "" {
+		percent, err := strconv.ParseFloat(v, 64)
+		if err != nil || percent < 0 || percent >= 100 {
+			log.Fatalf("Invalid PRICE_DROP_ALERT_PERCENT=%q", v)
+		}
+		adminService.PriceDropAlertPercent = percent
+	}
 	clientService := service.NewClientService(repo)
 	authService := service.NewAuthService(repo)
 
-	// Start background worker
-	go adminService.StartDailyCurrencyWorker()
+	// Start background worker; it stops with the server on SIGINT/SIGTERM.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	jobCfg, err := service.CurrencyJobConfigFromEnv()
+	if err != nil {
+		log.Fatal("Invalid currency worker configuration: ", err)
+	}
+	var worker sync.WaitGroup
+	worker.Add(1)
+	go func() {
+		defer worker.Done()
+		adminService.StartCurrencyWorker(ctx, jobCfg)
+	}()
+
+	leadService := service.NewLeadService(repo)
+	if leadService.Guard, err = service.LeadGuardFromEnv(); err != nil {
+		log.Fatal("Invalid lead anti-spam configuration: ", err)
+	}
 
-	h := handlers.NewHandler(authService, adminService, clientService)
+	h := handlers.NewHandler(authService, adminService, clientService, leadService)
 	mux := h.SetupRoutes()
 
 	// CORS Middleware
 	corsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, PATCH, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Total-Count, X-Page, X-Page-Size, Retry-After")
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -73,10 +138,43 @@ func main() {
 	})
 
 	port := getEnv("APP_PORT", "8080")
+	server := &http.Server{Addr: ":" + port, Handler: corsHandler}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		log.Println("Shutting down...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Graceful shutdown failed: %v", err)
+		}
+
+		// The server is closed, so no new manual run can start; let the
+		// scheduled and manual ones finish before the database is closed.
+		workerDone := make(chan struct{})
+		go func() {
+			worker.Wait()
+			close(workerDone)
+		}()
+		waitCtx, cancelWait := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancelWait()
+		select {
+		case <-workerDone:
+		case <-waitCtx.Done():
+			log.Println("Currency worker did not stop in time")
+		}
+		if err := adminService.WaitForCurrencyJobs(waitCtx); err != nil {
+			log.Printf("Manual currency run did not finish in time: %v", err)
+		}
+	}()
+
 	log.Printf("REST API Server started on http://localhost:%s", port)
-	if err := http.ListenAndServe(":"+port, corsHandler); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
+	<-shutdownDone
 }
 
 func getEnv(key, fallback string) string {
@@ -85,3 +183,15 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid %s=%q: expected an integer", key, value)
+	}
+	return n
+}
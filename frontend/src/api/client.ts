@@ -12,12 +12,33 @@ api.interceptors.request.use((config) => {
     return config;
 });
 
+const clearSession = () => {
+    localStorage.removeItem('token');
+    localStorage.removeItem('refresh_token');
+    window.location.href = '/login';
+};
+
+// Access tokens are short-lived: on a 401, try once to rotate the refresh token and replay the request.
 api.interceptors.response.use(
     (response) => response,
-    (error) => {
+    async (error) => {
+        const original = error.config;
+        const refreshToken = localStorage.getItem('refresh_token');
+        if (error.response?.status === 401 && refreshToken && original && !original._retry && !original.url?.includes('/token/refresh')) {
+            original._retry = true;
+            try {
+                const { data } = await api.post('/token/refresh', { refresh_token: refreshToken });
+                localStorage.setItem('token', data.token);
+                localStorage.setItem('refresh_token', data.refresh_token);
+                original.headers.Authorization = `Bearer ${data.token}`;
+                return api(original);
+            } catch {
+                clearSession();
+                return Promise.reject(error);
+            }
+        }
         if (error.response?.status === 401) {
-            localStorage.removeItem('token');
-            window.location.href = '/login';
+            clearSession();
         }
         return Promise.reject(error);
     }
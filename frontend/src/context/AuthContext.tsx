@@ -1,6 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import { User } from '../types';
 import { AuthContext } from './AuthContextInstance';
+import api from '../api/client';
 
 export const AuthProvider: React.FC<{ children: React.ReactNode }> = ({ children }) => {
     const [user, setUser] = useState<User | null>(null);
@@ -24,8 +25,11 @@ export const AuthProvider: React.FC<{ children: React.ReactNode }> = ({ children
         }
     }, []);
 
-    const login = (token: string) => {
+    const login = (token: string, refreshToken?: string) => {
         localStorage.setItem('token', token);
+        if (refreshToken) {
+            localStorage.setItem('refresh_token', refreshToken);
+        }
         try {
             const parts = token.split('.');
             if (parts.length === 3) {
@@ -38,7 +42,12 @@ export const AuthProvider: React.FC<{ children: React.ReactNode }> = ({ children
     };
 
     const logout = () => {
+        const refreshToken = localStorage.getItem('refresh_token');
+        if (localStorage.getItem('token')) {
+            api.post('/logout', { refresh_token: refreshToken }).catch(() => undefined);
+        }
         localStorage.removeItem('token');
+        localStorage.removeItem('refresh_token');
         localStorage.removeItem('expires');
         setUser(null);
     };
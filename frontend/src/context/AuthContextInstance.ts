@@ -3,7 +3,7 @@ import { User } from '../types';
 
 export interface AuthContextType {
     user: User | null;
-    login: (token: string) => void;
+    login: (token: string, refreshToken?: string) => void;
     logout: () => void;
     isAuthenticated: boolean;
 }
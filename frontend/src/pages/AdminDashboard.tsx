@@ -4,7 +4,19 @@ import { Link } from 'react-router-dom';
 import { ShieldCheck, Plus, Trash2, ExternalLink } from 'lucide-react';
 import toast, { Toaster } from 'react-hot-toast';
 import api, { getAssetUrl } from '../api/client';
-import { DashboardData, ApiError } from '../types';
+import { DashboardData, ApiError, InquiryDetails } from '../types';
+
+// describeInquiry summarizes the type-specific details of a lead.
+const describeInquiry = (details?: InquiryDetails): string => {
+    if (!details) return '';
+    const parts: string[] = [];
+    if (details.preferred_date) parts.push(details.preferred_date);
+    if (details.time_slot) parts.push(details.time_slot);
+    if (details.current_car) parts.push(`${details.current_car.year} ${details.current_car.make} ${details.current_car.model}`);
+    if (details.down_payment_kzt !== undefined) parts.push(`${details.down_payment_kzt.toLocaleString()} KZT down`);
+    if (details.term_months) parts.push(`${details.term_months} mo`);
+    return parts.join(' · ');
+};
 
 const AdminDashboard: React.FC = () => {
     const [data, setData] = useState<DashboardData | null>(null);
@@ -13,12 +25,8 @@ const AdminDashboard: React.FC = () => {
     const [showAddForm, setShowAddForm] = useState(false);
 
     // Form State
-    const [newCar, setNewCar] = useState({
-        vin: '',
-        model: '',
-        price: 0,
-        image_url: ''
-    });
+    const emptyCar = { vin: '', make: '', model: '', year: 0, mileage: 0, color: '', location: '', price: 0, currency: 'USD', image_url: '' };
+    const [newCar, setNewCar] = useState(emptyCar);
     const [uploading, setUploading] = useState(false);
 
     const fetchData = async () => {
@@ -86,11 +94,12 @@ const AdminDashboard: React.FC = () => {
             await api.post('/admin/cars', newCar);
             toast.success('Asset registered successfully');
             setShowAddForm(false);
-            setNewCar({ vin: '', model: '', price: 0, image_url: '' });
+            setNewCar(emptyCar);
             fetchData();
         } catch (error: unknown) {
-            const message = (error as ApiError).response?.data?.error || 'Registration failed';
-            toast.error(message);
+            const data = (error as ApiError).response?.data;
+            const fieldErrors = data?.fields ? Object.entries(data.fields).map(([field, msg]) => `${field}: ${msg}`).join('; ') : '';
+            toast.error(fieldErrors || data?.error || 'Registration failed');
         }
     };
 
@@ -159,15 +168,41 @@ const AdminDashboard: React.FC = () => {
                                 <form onSubmit={handleAddCar} style={{ display: 'grid', gridTemplateColumns: 'repeat(2, 1fr)', gap: '4rem' }}>
                                     <div className="form-group">
                                         <label>Asset VIN Designation</label>
-                                        <input type="text" placeholder="Unique ID" required value={newCar.vin} onChange={e => setNewCar({ ...newCar, vin: e.target.value })} />
+                                        <input type="text" placeholder="17-character VIN" required maxLength={17} value={newCar.vin} onChange={e => setNewCar({ ...newCar, vin: e.target.value.toUpperCase() })} />
+                                    </div>
+                                    <div className="form-group">
+                                        <label>Make</label>
+                                        <input type="text" placeholder="Brand" required value={newCar.make} onChange={e => setNewCar({ ...newCar, make: e.target.value })} />
+                                    </div>
+                                    <div className="form-group">
+                                        <label>Model Identity</label>
+                                        <input type="text" placeholder="Model Designation" required value={newCar.model} onChange={e => setNewCar({ ...newCar, model: e.target.value })} />
+                                    </div>
+                                    <div className="form-group">
+                                        <label>Model Year</label>
+                                        <input type="number" placeholder="e.g. 2024" value={newCar.year || ''} onChange={e => setNewCar({ ...newCar, year: Number(e.target.value) })} />
                                     </div>
                                     <div className="form-group">
-                                        <label>Make & Model Identity</label>
-                                        <input type="text" placeholder="Full Brand Designation" required value={newCar.model} onChange={e => setNewCar({ ...newCar, model: e.target.value })} />
+                                        <label>Mileage (km)</label>
+                                        <input type="number" min={0} value={newCar.mileage} onChange={e => setNewCar({ ...newCar, mileage: Number(e.target.value) })} />
                                     </div>
                                     <div className="form-group">
-                                        <label>Acquisition Cost / Price (USD)</label>
-                                        <input type="number" placeholder="Value in USD" required value={newCar.price} onChange={e => setNewCar({ ...newCar, price: Number(e.target.value) })} />
+                                        <label>Color</label>
+                                        <input type="text" placeholder="Exterior color" value={newCar.color} onChange={e => setNewCar({ ...newCar, color: e.target.value })} />
+                                    </div>
+                                    <div className="form-group">
+                                        <label>Location</label>
+                                        <input type="text" placeholder="Showroom / port" value={newCar.location} onChange={e => setNewCar({ ...newCar, location: e.target.value })} />
+                                    </div>
+                                    <div className="form-group">
+                                        <label>Acquisition Cost ({newCar.currency})</label>
+                                        <input type="number" placeholder={`Value in ${newCar.currency}`} required value={newCar.price} onChange={e => setNewCar({ ...newCar, price: Number(e.target.value) })} />
+                                    </div>
+                                    <div className="form-group">
+                                        <label>Purchase Currency</label>
+                                        <select value={newCar.currency} onChange={e => setNewCar({ ...newCar, currency: e.target.value })}>
+                                            {['USD', 'EUR', 'KRW', 'CNY', 'RUB'].map(c => <option key={c} value={c}>{c}</option>)}
+                                        </select>
                                     </div>
                                     <div className="form-group">
                                         <label>{uploading ? 'Processing local upload...' : 'Reference Visual (PNG/JPG)'}</label>
@@ -268,6 +303,7 @@ const AdminDashboard: React.FC = () => {
                                         <th>Private Client</th>
                                         <th>Secure Contact</th>
                                         <th>Target Asset</th>
+                                        <th>Inquiry</th>
                                         <th>Engagement Date</th>
                                         <th>Lead Status</th>
                                     </tr>
@@ -278,6 +314,10 @@ const AdminDashboard: React.FC = () => {
                                             <td style={{ fontWeight: 600 }}>{lead.customer_name}</td>
                                             <td style={{ color: 'var(--text-muted)', fontFamily: 'monospace' }}>{lead.customer_phone}</td>
                                             <td style={{ fontWeight: 600, color: 'var(--primary)' }}>{lead.car_model}</td>
+                                            <td style={{ fontSize: '0.85rem' }}>
+                                                <div style={{ fontWeight: 600, textTransform: 'uppercase', letterSpacing: '1px' }}>{lead.inquiry_type.replace(/_/g, ' ')}</div>
+                                                <div style={{ color: 'var(--text-muted)' }}>{describeInquiry(lead.details)}</div>
+                                            </td>
                                             <td style={{ color: 'var(--text-muted)', fontSize: '0.85rem' }}>
                                                 {lead.created_at ? new Date(lead.created_at).toLocaleDateString(undefined, { year: 'numeric', month: 'short', day: 'numeric' }) : 'LOG_READ_FAIL'}
                                             </td>
@@ -4,7 +4,7 @@ import { motion } from 'framer-motion';
 import { ChevronLeft, CheckCircle2 } from 'lucide-react';
 import toast, { Toaster } from 'react-hot-toast';
 import api, { getAssetUrl } from '../api/client';
-import { Car, ApiError } from '../types';
+import { Car, ApiError, InquiryDetails } from '../types';
 
 const CarDetails: React.FC = () => {
     const { id } = useParams<{ id: string }>();
@@ -16,8 +16,30 @@ const CarDetails: React.FC = () => {
 
     const [formData, setFormData] = useState({
         name: '',
-        phone: ''
+        phone: '',
+        inquiryType: 'test_drive',
+        preferredDate: '',
+        timeSlot: 'morning',
+        tradeInMake: '',
+        tradeInModel: '',
+        tradeInYear: '',
+        downPayment: '',
+        website: '' // honeypot, hidden from people
     });
+    const [formToken, setFormToken] = useState('');
+
+    const fetchFormToken = async () => {
+        try {
+            const response = await api.get('/leads/token');
+            setFormToken(response.data.token);
+        } catch (error) {
+            console.error('Error fetching form token:', error);
+        }
+    };
+
+    useEffect(() => {
+        fetchFormToken();
+    }, []);
 
     useEffect(() => {
         const fetchCar = async () => {
@@ -36,6 +58,22 @@ const CarDetails: React.FC = () => {
         fetchCar();
     }, [id]);
 
+    // inquiryDetails picks the fields the selected inquiry type requires.
+    const inquiryDetails = (): InquiryDetails => {
+        switch (formData.inquiryType) {
+            case 'test_drive':
+                return { preferred_date: formData.preferredDate, time_slot: formData.timeSlot };
+            case 'callback':
+                return { time_slot: formData.timeSlot };
+            case 'trade_in':
+                return { current_car: { make: formData.tradeInMake, model: formData.tradeInModel, year: Number(formData.tradeInYear) } };
+            case 'financing':
+                return { down_payment_kzt: Number(formData.downPayment) };
+            default:
+                return {};
+        }
+    };
+
     const handleSubmit = async (e: React.FormEvent) => {
         e.preventDefault();
         if (!car) return;
@@ -43,14 +81,22 @@ const CarDetails: React.FC = () => {
         setSubmitting(true);
         try {
             await api.post('/leads', {
-                car_model: `${car.make} ${car.model}`,
+                car_id: car.id,
                 name: formData.name,
-                phone: formData.phone
+                phone: formData.phone,
+                inquiry_type: formData.inquiryType,
+                details: inquiryDetails(),
+                website: formData.website,
+                form_token: formToken
             });
             setSubmitted(true);
             toast.success('Inquiry registered! Our sales team will contact you.');
-        } catch {
-            toast.error('Failed to submit. Please check your connection.');
+        } catch (error: unknown) {
+            const data = (error as ApiError).response?.data;
+            const fieldErrors = data?.fields ? Object.entries(data.fields).map(([field, msg]) => `${field}: ${msg}`).join('; ') : '';
+            toast.error(fieldErrors || data?.error || 'Failed to submit. Please check your connection.');
+            // Input errors keep the token; anything else may have used it up.
+            if (!fieldErrors) fetchFormToken();
         } finally {
             setSubmitting(false);
         }
@@ -124,10 +170,12 @@ const CarDetails: React.FC = () => {
                     <h1 style={{ fontSize: '3.5rem', fontWeight: 400, margin: '1rem 0 2rem', color: 'var(--text)' }}>{car.make} <span style={{ fontStyle: 'italic' }}>{car.model}</span></h1>
 
                     <div style={{ marginBottom: '3.5rem' }}>
-                        <div style={{ display: 'flex', alignItems: 'baseline', gap: '1rem' }}>
-                            <span style={{ fontSize: '3rem', fontFamily: 'var(--font-serif)', color: 'var(--text)' }}>${car.price_usd?.toLocaleString()}</span>
-                            <span style={{ color: 'var(--text-muted)', fontSize: '0.9rem', letterSpacing: '1px' }}>US DOLLARS</span>
-                        </div>
+                        {car.price_usd && (
+                            <div style={{ display: 'flex', alignItems: 'baseline', gap: '1rem' }}>
+                                <span style={{ fontSize: '3rem', fontFamily: 'var(--font-serif)', color: 'var(--text)' }}>${car.price_usd.toLocaleString()}</span>
+                                <span style={{ color: 'var(--text-muted)', fontSize: '0.9rem', letterSpacing: '1px' }}>US DOLLARS</span>
+                            </div>
+                        )}
                         {car.price_kzt && (
                             <div style={{ color: 'var(--primary)', fontWeight: 600, marginTop: '0.75rem', fontSize: '1.25rem' }}>
                                 ₸ {car.price_kzt.toLocaleString()} KZT
@@ -172,6 +220,67 @@ const CarDetails: React.FC = () => {
                                         onChange={(e) => setFormData({ ...formData, phone: e.target.value })}
                                     />
                                 </div>
+                                <div className="form-group">
+                                    <label>Inquiry</label>
+                                    <select value={formData.inquiryType} onChange={(e) => setFormData({ ...formData, inquiryType: e.target.value })}>
+                                        <option value="test_drive">Test Drive</option>
+                                        <option value="price_quote">Price Quote</option>
+                                        <option value="trade_in">Trade-In</option>
+                                        <option value="financing">Financing</option>
+                                        <option value="callback">Callback</option>
+                                    </select>
+                                </div>
+                                {formData.inquiryType === 'test_drive' && (
+                                    <div className="form-group">
+                                        <label>Preferred Date</label>
+                                        <input
+                                            type="date"
+                                            required
+                                            value={formData.preferredDate}
+                                            onChange={(e) => setFormData({ ...formData, preferredDate: e.target.value })}
+                                        />
+                                    </div>
+                                )}
+                                {(formData.inquiryType === 'test_drive' || formData.inquiryType === 'callback') && (
+                                    <div className="form-group">
+                                        <label>Preferred Time</label>
+                                        <select value={formData.timeSlot} onChange={(e) => setFormData({ ...formData, timeSlot: e.target.value })}>
+                                            <option value="morning">Morning</option>
+                                            <option value="afternoon">Afternoon</option>
+                                            <option value="evening">Evening</option>
+                                        </select>
+                                    </div>
+                                )}
+                                {formData.inquiryType === 'trade_in' && (
+                                    <div className="form-group">
+                                        <label>Current Vehicle</label>
+                                        <input type="text" placeholder="Make" required value={formData.tradeInMake} onChange={(e) => setFormData({ ...formData, tradeInMake: e.target.value })} />
+                                        <input type="text" placeholder="Model" required value={formData.tradeInModel} onChange={(e) => setFormData({ ...formData, tradeInModel: e.target.value })} />
+                                        <input type="number" placeholder="Year" required value={formData.tradeInYear} onChange={(e) => setFormData({ ...formData, tradeInYear: e.target.value })} />
+                                    </div>
+                                )}
+                                {formData.inquiryType === 'financing' && (
+                                    <div className="form-group">
+                                        <label>Down Payment (KZT)</label>
+                                        <input
+                                            type="number"
+                                            min="0"
+                                            required
+                                            value={formData.downPayment}
+                                            onChange={(e) => setFormData({ ...formData, downPayment: e.target.value })}
+                                        />
+                                    </div>
+                                )}
+                                <input
+                                    type="text"
+                                    name="website"
+                                    tabIndex={-1}
+                                    autoComplete="off"
+                                    aria-hidden="true"
+                                    style={{ position: 'absolute', left: '-10000px', width: '1px', height: '1px', overflow: 'hidden' }}
+                                    value={formData.website}
+                                    onChange={(e) => setFormData({ ...formData, website: e.target.value })}
+                                />
                                 <button type="submit" className="btn-primary" style={{ width: '100%', justifyContent: 'center', marginTop: '1.5rem', padding: '1.25rem' }} disabled={submitting}>
                                     {submitting ? 'PROCESSING...' : 'REQUEST ACQUISITION DATA'}
                                 </button>
@@ -5,16 +5,20 @@ import { Link } from 'react-router-dom';
 import api, { getAssetUrl } from '../api/client';
 import { Car } from '../types';
 
+const PAGE_SIZE = 24;
+
 const Catalog: React.FC = () => {
     const [cars, setCars] = useState<Car[]>([]);
     const [loading, setLoading] = useState(true);
+    const [page, setPage] = useState(1);
+    const [total, setTotal] = useState(0);
 
     useEffect(() => {
         const fetchCars = async () => {
             try {
-                // Assuming your backend has a public GET /cars endpoint
-                const response = await api.get('/cars');
+                const response = await api.get('/cars', { params: { page, page_size: PAGE_SIZE } });
                 setCars(response.data);
+                setTotal(Number(response.headers['x-total-count'] ?? response.data.length));
             } catch (error) {
                 console.error('Error fetching catalog:', error);
             } finally {
@@ -22,7 +26,9 @@ const Catalog: React.FC = () => {
             }
         };
         fetchCars();
-    }, []);
+    }, [page]);
+
+    const totalPages = Math.max(1, Math.ceil(total / PAGE_SIZE));
 
     if (loading) {
         return (
@@ -91,6 +97,13 @@ const Catalog: React.FC = () => {
     </div>
     )}
     </div>
+    {totalPages > 1 && (
+        <div style={{ display: 'flex', justifyContent: 'center', alignItems: 'center', gap: '1.5rem', marginTop: '3rem' }}>
+        <button className="btn-primary" disabled={page <= 1} onClick={() => setPage(page - 1)}>PREVIOUS</button>
+        <span style={{ color: 'var(--text-muted)', letterSpacing: '2px', fontSize: '0.8rem' }}>PAGE {page} / {totalPages}</span>
+        <button className="btn-primary" disabled={page >= totalPages} onClick={() => setPage(page + 1)}>NEXT</button>
+        </div>
+    )}
     </div>
 );
 };
@@ -20,7 +20,7 @@ const Login: React.FC = () => {
 
         try {
             const response = await api.post('/login', { username, password });
-            login(response.data.token);
+            login(response.data.token, response.data.refresh_token);
             toast.success('Session Authenticated');
             navigate('/admin');
         } catch (error: unknown) {
@@ -3,6 +3,9 @@ export interface Car {
   vin: string;
   make: string;
   model: string;
+  year?: number;
+  mileage?: number;
+  color?: string;
   price_usd?: number;
   price_kzt?: number;
   status: 'available' | 'transit' | 'reserved' | 'sold';
@@ -14,16 +17,31 @@ export interface Car {
 export interface AuthResponse {
   token: string;
   expires: string;
+  refresh_token: string;
+  refresh_expires: string;
+}
+
+export interface InquiryDetails {
+  preferred_date?: string;
+  time_slot?: string;
+  current_car?: { make: string; model: string; year: number; mileage?: number };
+  down_payment_kzt?: number;
+  term_months?: number;
 }
 
 export interface Lead {
   id: string;
+  car_id?: string;
   car_model: string;
   customer_name: string;
   customer_phone: string;
   inquiry_type: string;
+  details?: InquiryDetails;
   status: string;
+  assigned_user_id?: string;
+  assigned_username?: string;
   created_at: string;
+  updated_at?: string;
 }
 
 export interface DashboardData {
@@ -40,6 +58,7 @@ export interface ApiError {
   response?: {
     data?: {
       error?: string;
+      fields?: Record<string, string>;
     };
     status?: number;
   };
@@ -0,0 +1,125 @@
+package antispam
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTokenInvalid = errors.New("form token is missing or invalid")
+	ErrTokenExpired = errors.New("form token has expired; reload the form")
+	ErrTokenTooNew  = errors.New("form submitted too quickly; please wait a moment and retry")
+	ErrTokenUsed    = errors.New("form token has already been used; reload the form")
+)
+
+// FormTokens issues and checks HMAC-signed form tokens. A token proves the client
+// loaded the form first: it must be at least MinAge old (bots submit instantly),
+// no older than MaxAge, and is accepted once.
+type FormTokens struct {
+	MinAge time.Duration
+	MaxAge time.Duration
+
+	secret []byte
+	mu     sync.Mutex
+	used   map[string]time.Time // token -> expiry, pruned as tokens expire
+}
+
+// NewFormTokens signs tokens with secret. An empty secret is replaced with a
+// random one, so tokens do not survive a restart.
+func NewFormTokens(secret []byte, minAge, maxAge time.Duration) *FormTokens {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		rand.Read(secret)
+	}
+	return &FormTokens{MinAge: minAge, MaxAge: maxAge, secret: secret, used: map[string]time.Time{}}
+}
+
+// Issue returns a new token of the form "<unix time>.<nonce>.<signature>".
+func (t *FormTokens) Issue(now time.Time) string {
+	nonce := make([]byte, 12)
+	rand.Read(nonce)
+	payload := strconv.FormatInt(now.Unix(), 10) + "." + hex.EncodeToString(nonce)
+	return payload + "." + t.sign(payload)
+}
+
+// Check validates a token without using it up, so a submission that fails
+// validation can be corrected and resent with the same token.
+func (t *FormTokens) Check(token string, now time.Time) error {
+	if _, err := t.validate(token, now); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, used := t.used[token]; used {
+		return ErrTokenUsed
+	}
+	return nil
+}
+
+// Consume checks a token and marks it as used.
+func (t *FormTokens) Consume(token string, now time.Time) error {
+	issued, err := t.validate(token, now)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, used := t.used[token]; used {
+		return ErrTokenUsed
+	}
+	for tok, expiry := range t.used {
+		if now.After(expiry) {
+			delete(t.used, tok)
+		}
+	}
+	t.used[token] = issued.Add(t.MaxAge)
+	return nil
+}
+
+// validate checks the signature and age of a token and returns its issue time.
+func (t *FormTokens) validate(token string, now time.Time) (time.Time, error) {
+	issued, err := t.verify(token)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch age := now.Sub(issued); {
+	case age < t.MinAge:
+		return time.Time{}, ErrTokenTooNew
+	case age > t.MaxAge:
+		return time.Time{}, ErrTokenExpired
+	}
+	return issued, nil
+}
+
+// verify checks the signature and returns the issue time.
+func (t *FormTokens) verify(token string) (time.Time, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return time.Time{}, ErrTokenInvalid
+	}
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(t.sign(payload))) {
+		return time.Time{}, ErrTokenInvalid
+	}
+	unix, err := strconv.ParseInt(strings.SplitN(payload, ".", 2)[0], 10, 64)
+	if err != nil {
+		return time.Time{}, ErrTokenInvalid
+	}
+	return time.Unix(unix, 0), nil
+}
+
+func (t *FormTokens) sign(payload string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
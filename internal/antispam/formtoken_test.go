@@ -0,0 +1,113 @@
+package antispam
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestTokens() *FormTokens {
+	return NewFormTokens([]byte("test secret"), 3*time.Second, time.Hour)
+}
+
+func TestFormTokenLifecycle(t *testing.T) {
+	tokens := newTestTokens()
+	issued := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	token := tokens.Issue(issued)
+
+	if err := tokens.Check(token, issued.Add(time.Second)); !errors.Is(err, ErrTokenTooNew) {
+		t.Errorf("Check after 1s = %v, want ErrTokenTooNew", err)
+	}
+	if err := tokens.Consume(token, issued.Add(time.Second)); !errors.Is(err, ErrTokenTooNew) {
+		t.Errorf("Consume after 1s = %v, want ErrTokenTooNew", err)
+	}
+
+	now := issued.Add(10 * time.Second)
+	// Check does not use the token up, so a corrected submission may resend it.
+	for i := 0; i < 2; i++ {
+		if err := tokens.Check(token, now); err != nil {
+			t.Fatalf("Check #%d = %v", i+1, err)
+		}
+	}
+	if err := tokens.Consume(token, now); err != nil {
+		t.Fatalf("Consume = %v", err)
+	}
+	if err := tokens.Check(token, now); !errors.Is(err, ErrTokenUsed) {
+		t.Errorf("Check after Consume = %v, want ErrTokenUsed", err)
+	}
+	if err := tokens.Consume(token, now); !errors.Is(err, ErrTokenUsed) {
+		t.Errorf("second Consume = %v, want ErrTokenUsed", err)
+	}
+}
+
+func TestFormTokenExpired(t *testing.T) {
+	tokens := newTestTokens()
+	issued := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	token := tokens.Issue(issued)
+
+	if err := tokens.Check(token, issued.Add(time.Hour)); err != nil {
+		t.Errorf("Check at MaxAge = %v", err)
+	}
+	if err := tokens.Check(token, issued.Add(time.Hour+time.Second)); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Check after MaxAge = %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestFormTokenInvalid(t *testing.T) {
+	tokens := newTestTokens()
+	issued := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	now := issued.Add(time.Minute)
+	token := tokens.Issue(issued)
+
+	_, rest, _ := strings.Cut(token, ".")
+	backdated := strconv.FormatInt(issued.Add(-time.Minute).Unix(), 10) + "." + rest
+
+	otherSecret := NewFormTokens([]byte("other secret"), 3*time.Second, time.Hour).Issue(issued)
+
+	for name, tok := range map[string]string{
+		"empty":         "",
+		"no signature":  "garbage",
+		"bad signature": token[:len(token)-1] + "x",
+		"backdated":     backdated,
+		"other secret":  otherSecret,
+	} {
+		if err := tokens.Check(tok, now); !errors.Is(err, ErrTokenInvalid) {
+			t.Errorf("Check(%s) = %v, want ErrTokenInvalid", name, err)
+		}
+	}
+}
+
+func TestFormTokensUnique(t *testing.T) {
+	tokens := newTestTokens()
+	now := time.Now()
+	if a, b := tokens.Issue(now), tokens.Issue(now); a == b {
+		t.Errorf("two tokens issued at the same time are equal: %q", a)
+	}
+}
+
+func TestFormTokensRandomSecret(t *testing.T) {
+	issued := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	a := NewFormTokens(nil, 0, time.Hour)
+	b := NewFormTokens(nil, 0, time.Hour)
+	if err := b.Check(a.Issue(issued), issued); !errors.Is(err, ErrTokenInvalid) {
+		t.Errorf("token from another instance without a secret = %v, want ErrTokenInvalid", err)
+	}
+}
+
+func TestConsumePrunesExpiredTokens(t *testing.T) {
+	tokens := newTestTokens()
+	issued := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	if err := tokens.Consume(tokens.Issue(issued), issued.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	later := issued.Add(2 * time.Hour)
+	if err := tokens.Consume(tokens.Issue(later), later.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens.used) != 1 {
+		t.Errorf("%d used tokens remembered, want 1", len(tokens.used))
+	}
+}
@@ -0,0 +1,67 @@
+package antispam
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	// Three requests per three minutes: a burst of three, then one a minute.
+	l := NewLimiter(3, 3*time.Minute)
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("10.0.0.1", start); !ok {
+			t.Fatalf("request %d of the burst was refused", i+1)
+		}
+	}
+	if ok, retry := l.Allow("10.0.0.1", start); ok || retry != time.Minute {
+		t.Errorf("Allow after burst = %v, %s; want false, 1m0s", ok, retry)
+	}
+	if ok, retry := l.Allow("10.0.0.1", start.Add(30*time.Second)); ok || retry != 30*time.Second {
+		t.Errorf("Allow after 30s = %v, %s; want false, 30s", ok, retry)
+	}
+	if ok, _ := l.Allow("10.0.0.1", start.Add(time.Minute)); !ok {
+		t.Error("Allow after a minute refused, want one refilled token")
+	}
+	if ok, _ := l.Allow("10.0.0.1", start.Add(time.Minute)); ok {
+		t.Error("Allow twice after a minute succeeded, want only one refilled token")
+	}
+
+	if ok, _ := l.Allow("10.0.0.2", start); !ok {
+		t.Error("another key was refused, want separate buckets")
+	}
+}
+
+func TestLimiterRefillCapped(t *testing.T) {
+	l := NewLimiter(2, time.Minute)
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	l.Allow("+77011234567", start)
+
+	// A long pause refills the bucket only up to Limit.
+	later := start.Add(30 * time.Second)
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("+77011234567", later.Add(10*time.Minute)); !ok {
+			t.Fatalf("request %d after a pause was refused", i+1)
+		}
+	}
+	if ok, _ := l.Allow("+77011234567", later.Add(10*time.Minute)); ok {
+		t.Error("bucket refilled beyond Limit")
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	l := NewLimiter(2, time.Minute)
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	l.Allow("10.0.0.1", start)
+	l.Allow("10.0.0.2", start.Add(30*time.Second))
+
+	// Sweeps run at most once per Window and drop buckets idle for a whole Window.
+	l.Allow("10.0.0.3", start.Add(70*time.Second))
+	if _, ok := l.buckets["10.0.0.1"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if len(l.buckets) != 2 {
+		t.Errorf("%d buckets after sweep, want 2", len(l.buckets))
+	}
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "slices"
+
+// SupportedCurrencies are the foreign currencies cars can be bought in and
+// catalog prices can be displayed in. Every price is derived through KZT.
+var SupportedCurrencies = []string{"USD", "EUR", "KRW", "CNY", "RUB"}
+
+// IsSupportedCurrency reports whether code is one of SupportedCurrencies.
+func IsSupportedCurrency(code string) bool {
+	return slices.Contains(SupportedCurrencies, code)
+}
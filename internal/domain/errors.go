@@ -3,6 +3,27 @@ package domain
 import "errors"
 
 var (
-	ErrCarNotFound     = errors.New("car not found")
-	ErrCarNotAvailable = errors.New("car is not available for booking")
+	ErrCarNotFound      = errors.New("car not found")
+	ErrCarNotAvailable  = errors.New("car is not available for booking")
+	ErrVersionConflict  = errors.New("car was modified by someone else; reload and retry")
+	ErrDuplicateVIN     = errors.New("a car with this VIN already exists")
+	ErrLeadNotFound     = errors.New("lead not found")
+	ErrLeadChanged      = errors.New("lead was modified by someone else; reload and retry")
+	ErrInvalidAssignee  = errors.New("leads can only be assigned to active admins or managers")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrUserDisabled     = errors.New("user account is disabled")
+	ErrNotWatching      = errors.New("car is not on the watchlist")
+	ErrUsernameTaken    = errors.New("username already taken")
+	ErrInvalidRole      = errors.New("role must be one of: admin, manager, user")
+	ErrWeakPassword     = errors.New("password must be at least 6 characters")
+	ErrSelfLockout      = errors.New("admins cannot disable or demote their own account")
+	ErrInvalidToken     = errors.New("invalid or expired token")
+	ErrTokenRevoked     = errors.New("token has been revoked")
+	ErrRateUnavailable  = errors.New("exchange rate not available for currency")
+	ErrPriceLocked      = errors.New("car price is locked by a manual override")
+	ErrJobRunning       = errors.New("job is already running")
+	ErrRuleNotFound     = errors.New("pricing rule not found")
+	ErrDuplicateRule    = errors.New("a pricing rule for this make and category already exists")
+	ErrSpamDetected     = errors.New("submission rejected as spam")
+	ErrInvalidFormToken = errors.New("invalid form token")
 )
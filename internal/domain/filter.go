@@ -0,0 +1,42 @@
+package domain
+
+// Catalog sort fields accepted by CarFilter.Sort.
+const (
+	SortNewest  = "created_at"
+	SortPrice   = "price"
+	SortYear    = "year"
+	SortMileage = "mileage"
+	SortMake    = "make"
+)
+
+// CarSortFields lists the valid values of CarFilter.Sort.
+var CarSortFields = []string{SortNewest, SortPrice, SortYear, SortMileage, SortMake}
+
+// Catalog page size bounds.
+const (
+	DefaultPageSize = 24
+	MaxPageSize     = 100
+)
+
+// CarFilter narrows, orders and pages a car listing. Zero values mean "no restriction".
+type CarFilter struct {
+	Make        string
+	Model       string // matched as a case-insensitive substring
+	Statuses    []string
+	MinPriceKZT float64
+	MaxPriceKZT float64
+	MinYear     int
+	MaxYear     int
+	Sort        string // one of CarSortFields; defaults to SortNewest
+	Desc        bool
+	Page        int // 1-based
+	PageSize    int
+}
+
+// Offset is the number of rows skipped before the current page.
+func (f CarFilter) Offset() int {
+	if f.Page <= 1 {
+		return 0
+	}
+	return (f.Page - 1) * f.PageSize
+}
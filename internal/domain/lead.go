@@ -0,0 +1,75 @@
+package domain
+
+import "slices"
+
+// Lead pipeline stages, mirrored by the CHECK constraint on leads.status.
+const (
+	LeadNew                = "new"
+	LeadContacted          = "contacted"
+	LeadTestDriveScheduled = "test_drive_scheduled"
+	LeadNegotiating        = "negotiating"
+	LeadWon                = "won"
+	LeadLost               = "lost"
+)
+
+// leadTransitions lists the stages reachable from each stage. A lost lead can be
+// reopened as contacted; a won lead is final.
+var leadTransitions = map[string][]string{
+	LeadNew:                {LeadContacted, LeadLost},
+	LeadContacted:          {LeadTestDriveScheduled, LeadNegotiating, LeadLost},
+	LeadTestDriveScheduled: {LeadContacted, LeadNegotiating, LeadLost},
+	LeadNegotiating:        {LeadTestDriveScheduled, LeadWon, LeadLost},
+	LeadLost:               {LeadContacted},
+}
+
+// IsLeadStatus reports whether status is a known pipeline stage.
+func IsLeadStatus(status string) bool {
+	switch status {
+	case LeadNew, LeadContacted, LeadTestDriveScheduled, LeadNegotiating, LeadWon, LeadLost:
+		return true
+	}
+	return false
+}
+
+// AllowedLeadTransitions returns the stages a lead may move to from the given one.
+func AllowedLeadTransitions(from string) []string {
+	return append([]string(nil), leadTransitions[from]...)
+}
+
+// ValidateLeadTransition checks a stage change against the lead pipeline.
+// Failures are reported as *StatusTransitionError.
+func ValidateLeadTransition(from, to string) error {
+	for _, next := range leadTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return &StatusTransitionError{From: from, To: to, Allowed: AllowedLeadTransitions(from)}
+}
+
+// Inquiry types a customer can submit through the lead form.
+const (
+	InquiryTestDrive  = "test_drive"
+	InquiryPriceQuote = "price_quote"
+	InquiryTradeIn    = "trade_in"
+	InquiryFinancing  = "financing"
+	InquiryCallback   = "callback"
+)
+
+// InquiryTypes lists every inquiry type, mirrored by the CHECK constraint on leads.inquiry_type.
+var InquiryTypes = []string{InquiryTestDrive, InquiryPriceQuote, InquiryTradeIn, InquiryFinancing, InquiryCallback}
+
+// IsInquiryType reports whether t is a known inquiry type.
+func IsInquiryType(t string) bool {
+	return slices.Contains(InquiryTypes, t)
+}
+
+// Preferred time slots for test drives and callbacks.
+const (
+	SlotMorning   = "morning"
+	SlotAfternoon = "afternoon"
+	SlotEvening   = "evening"
+)
+
+// TimeSlots lists the preferred time slots a customer can pick.
+var TimeSlots = []string{SlotMorning, SlotAfternoon, SlotEvening}
@@ -1,57 +1,365 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type User struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"-"`
-	Role     string `json:"role"`
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email,omitempty"`
+	Password  string    `json:"-"`
+	Role      string    `json:"role"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// User roles, mirrored by the CHECK constraint on users.role.
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleUser    = "user"
+)
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleManager || role == RoleUser
+}
+
+// Principal describes the authenticated caller of a request.
+type Principal struct {
+	UserID    string
+	Username  string
+	Role      string
+	TokenID   string
+	ExpiresAt time.Time
+}
+
+// RefreshToken is a long-lived, single-use credential exchanged for a new access token.
+// Only the SHA-256 hash of the token value is stored.
+type RefreshToken struct {
+	ID              string
+	UserID          string
+	TokenHash       string
+	AccessTokenID   string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+	RevokedAt       *time.Time
+}
+
+// TokenPair is returned to clients on login and refresh.
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires"`
 }
 
 type Lead struct {
-	ID            string    `json:"id"`
-	CarModel      string    `json:"car_model"`
-	CustomerName  string    `json:"customer_name"`
-	CustomerPhone string    `json:"customer_phone"`
-	InquiryType   string    `json:"inquiry_type"`
-	Status        string    `json:"status"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID               string         `json:"id"`
+	CarID            string         `json:"car_id,omitempty"`
+	CarModel         string         `json:"car_model"`
+	CustomerName     string         `json:"customer_name"`
+	CustomerPhone    string         `json:"customer_phone"`
+	InquiryType      string         `json:"inquiry_type"`
+	Details          InquiryDetails `json:"details"`
+	Status           string         `json:"status"`
+	AssignedUserID   string         `json:"assigned_user_id,omitempty"`
+	AssignedUsername string         `json:"assigned_username,omitempty"`
+	Notes            []LeadNote     `json:"notes,omitempty"`
+	Touches          []LeadTouch    `json:"touches,omitempty"`
+	LastInquiryAt    time.Time      `json:"last_inquiry_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// LeadPatch changes a lead's stage and/or salesperson; nil fields are left unchanged.
+// An empty AssignedUserID unassigns the lead.
+type LeadPatch struct {
+	Status         *string `json:"status"`
+	AssignedUserID *string `json:"assigned_user_id"`
+}
+
+// InquiryDetails holds the fields specific to a lead's inquiry type; fields that
+// don't apply to the type are left empty. Stored as leads.details JSONB.
+type InquiryDetails struct {
+	PreferredDate  string      `json:"preferred_date,omitempty"`   // test_drive: YYYY-MM-DD
+	TimeSlot       string      `json:"time_slot,omitempty"`        // test_drive, callback: morning/afternoon/evening
+	CurrentCar     *TradeInCar `json:"current_car,omitempty"`      // trade_in
+	DownPaymentKZT *float64    `json:"down_payment_kzt,omitempty"` // financing
+	TermMonths     int         `json:"term_months,omitempty"`      // financing, optional
+}
+
+// TradeInCar is the car a customer offers in part exchange.
+type TradeInCar struct {
+	Make    string `json:"make"`
+	Model   string `json:"model"`
+	Year    int    `json:"year"`
+	Mileage int    `json:"mileage,omitempty"`
+}
+
+// LeadTouch is one inquiry submitted by a customer. Repeat inquiries from the same
+// phone are merged into the open lead, so a lead can have several touches.
+type LeadTouch struct {
+	ID           string         `json:"id"`
+	LeadID       string         `json:"lead_id"`
+	CarID        string         `json:"car_id,omitempty"`
+	CarModel     string         `json:"car_model"`
+	CustomerName string         `json:"customer_name"`
+	InquiryType  string         `json:"inquiry_type"`
+	Details      InquiryDetails `json:"details"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// LeadNote is a salesperson's note on a lead, e.g. the outcome of a call.
+type LeadNote struct {
+	ID             string    `json:"id"`
+	LeadID         string    `json:"lead_id"`
+	AuthorID       string    `json:"author_id,omitempty"`
+	AuthorUsername string    `json:"author_username,omitempty"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type Car struct {
-	ID        string    `json:"id"`
-	VIN       string    `json:"vin"`
-	Make      string    `json:"make"`
-	Model     string    `json:"model"`
-	PriceUSD  float64   `json:"price_usd"`
-	PriceKZT  float64   `json:"price_kzt"`
-	Status    string    `json:"status"`
-	ImageURL  string    `json:"image_url"`
-	CreatedAt time.Time `json:"created_at"`
+	ID               string     `json:"id"`
+	VIN              string     `json:"vin"`
+	Make             string     `json:"make"`
+	Model            string     `json:"model"`
+	Category         string     `json:"category,omitempty"`
+	Year             int        `json:"year,omitempty"`
+	Mileage          int        `json:"mileage"`
+	Color            string     `json:"color,omitempty"`
+	Location         string     `json:"location,omitempty"`
+	Description      string     `json:"description,omitempty"`
+	PurchaseCurrency string     `json:"purchase_currency"`
+	PurchasePrice    float64    `json:"purchase_price"`
+	PriceUSD         float64    `json:"price_usd"`
+	PriceKZT         float64    `json:"price_kzt"`
+	PriceLocked      bool       `json:"price_locked"`
+	PriceLockedUntil *time.Time `json:"price_locked_until,omitempty"`
+	Status           string     `json:"status"`
+	StatusChangedBy  string     `json:"status_changed_by,omitempty"`
+	StatusChangedAt  *time.Time `json:"status_changed_at,omitempty"`
+	SoldAt           *time.Time `json:"sold_at,omitempty"`
+	ImageURL         string     `json:"image_url"`
+	Version          int        `json:"version"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// PriceLockActive reports whether a manual price override still shields the car
+// from automatic repricing at now. A lock without an expiry never lapses.
+func (c *Car) PriceLockActive(now time.Time) bool {
+	return c.PriceLocked && (c.PriceLockedUntil == nil || c.PriceLockedUntil.After(now))
+}
+
+// StatusChange is a request to move a car to a new lifecycle status.
+type StatusChange struct {
+	CarID   string
+	To      string
+	ActorID string
+	Reason  string
+}
+
+// CarStatusEvent is one entry in a car's status history.
+type CarStatusEvent struct {
+	ID            string    `json:"id"`
+	CarID         string    `json:"car_id"`
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	ActorID       string    `json:"actor_id,omitempty"`
+	ActorUsername string    `json:"actor_username,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// CarSearchHit is a full-text search match. Headline is an excerpt with matched
+// terms wrapped in <mark> tags; every other character is HTML-escaped.
+type CarSearchHit struct {
+	Car      Car
+	Rank     float64
+	Headline string
+}
+
+// CarPatch holds the editable car fields; nil fields are left unchanged.
+// PriceUSD can only be set directly on cars bought in USD; other cars derive it
+// from PurchasePrice and PurchaseCurrency.
+type CarPatch struct {
+	VIN         *string  `json:"vin"`
+	Make        *string  `json:"make"`
+	Model       *string  `json:"model"`
+	Category    *string  `json:"category"`
+	Year        *int     `json:"year"`
+	Mileage     *int     `json:"mileage"`
+	Color       *string  `json:"color"`
+	Location    *string  `json:"location"`
+	Description *string  `json:"description"`
+	PriceUSD    *float64 `json:"price_usd"`
+	ImageURL    *string  `json:"image_url"`
+
+	PurchasePrice    *float64 `json:"purchase_price"`
+	PurchaseCurrency *string  `json:"purchase_currency"`
 }
 
 type PublicCar struct {
-	ID       string  `json:"id"`
-	Make     string  `json:"make"`
-	Model    string  `json:"model"`
-	PriceKZT float64 `json:"price_kzt"`
-	Status   string  `json:"status"`
-	ImageURL string  `json:"image_url"`
+	ID          string  `json:"id"`
+	Make        string  `json:"make"`
+	Model       string  `json:"model"`
+	Year        int     `json:"year,omitempty"`
+	Mileage     int     `json:"mileage"`
+	Color       string  `json:"color,omitempty"`
+	Location    string  `json:"location,omitempty"`
+	Description string  `json:"description,omitempty"`
+	PriceKZT    float64 `json:"price_kzt"`
+	Price       float64 `json:"price,omitempty"`
+	Currency    string  `json:"currency,omitempty"`
+	Status      string  `json:"status"`
+	ImageURL    string  `json:"image_url"`
+}
+
+// CurrencyKZT is the quote currency of every stored exchange rate.
+const CurrencyKZT = "KZT"
+
+// ExchangeRate is the official price of one unit of Currency in KZT.
+type ExchangeRate struct {
+	ID        string    `json:"id,omitempty"`
+	Currency  string    `json:"currency"`
+	Quote     string    `json:"quote"`
+	Rate      float64   `json:"rate"`
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// RateQuery filters the exchange-rate history. Zero values mean "no bound".
+type RateQuery struct {
+	Currency string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// PriceChange records a KZT price update and, for worker updates, the rate that produced it.
+type PriceChange struct {
+	ID             string    `json:"id"`
+	CarID          string    `json:"car_id"`
+	OldPriceKZT    float64   `json:"old_price_kzt"`
+	NewPriceKZT    float64   `json:"new_price_kzt"`
+	ExchangeRateID string    `json:"exchange_rate_id,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	ChangedBy      string    `json:"changed_by,omitempty"`
+	ChangedAt      time.Time `json:"changed_at"`
+
+	// Lock marks a manual override: the car keeps this price until LockedUntil
+	// (or until unlocked, when nil). Changes without Lock fail on locked cars.
+	Lock        bool       `json:"-"`
+	LockedUntil *time.Time `json:"-"`
+}
+
+// PricingRule turns a purchase cost into a retail KZT price. Empty Make or
+// Category match any car; the most specific matching rule wins.
+type PricingRule struct {
+	ID              string    `json:"id"`
+	Make            string    `json:"make,omitempty"`
+	Category        string    `json:"category,omitempty"`
+	MarkupPercent   float64   `json:"markup_percent"`
+	CustomsFeeKZT   float64   `json:"customs_fee_kzt"`
+	LogisticsFeeKZT float64   `json:"logistics_fee_kzt"`
+	VATPercent      float64   `json:"vat_percent"`
+	RoundingStepKZT float64   `json:"rounding_step_kzt"`
+	MinMarginKZT    float64   `json:"min_margin_kzt"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Watch is a customer's subscription to a car, with the state last reported to them.
+// BaselinePriceKZT is the price at watch time or at the last price-drop alert.
+type Watch struct {
+	UserID           string
+	Username         string
+	Email            string
+	CarID            string
+	CarMake          string
+	CarModel         string
+	PriceKZT         float64
+	Status           string
+	BaselinePriceKZT float64
+	LastStatus       string
+}
+
+// Notification is a message to a single user.
+type Notification struct {
+	Username string
+	Email    string
+	Subject  string
+	Body     string
+}
+
+// Notifier delivers notifications to users, e.g. by email.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// ExchangeRateProvider supplies KZT exchange rates to the currency worker.
+// FetchRates reads the source once and returns the rates it has for the given
+// currencies; currencies the source does not quote are left out of the map.
+type ExchangeRateProvider interface {
+	FetchRate(ctx context.Context, currency string) (*ExchangeRate, error)
+	FetchRates(ctx context.Context, currencies []string) (map[string]*ExchangeRate, error)
 }
 
 type Repository interface {
 	CreateUser(u *User) error
 	GetUserByUsername(username string) (*User, error)
-	CreateLead(lead *Lead) error
+	GetUserByID(id string) (*User, error)
+	ListUsers() ([]User, error)
+	UpdateUserRole(id string, role string) error
+	SetUserDisabled(id string, disabled bool) error
+	UpdatePassword(id string, passwordHash string) error
+	UpdateEmail(id string, email string) error
+	CreateRefreshToken(t *RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*RefreshToken, error)
+	RotateRefreshToken(oldID string, next *RefreshToken) error
+	RevokeRefreshToken(hash string) error
+	RevokeAccessToken(jti string, userID string, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	RevokeAllUserSessions(userID string) error
+	IntakeLead(lead *Lead, dedupWindow time.Duration) (merged bool, err error)
+	GetLeadTouches(leadID string) ([]LeadTouch, error)
 	GetAllLeads() ([]Lead, error)
+	GetLeadByID(id string) (*Lead, error)
+	GetLeadsByCar(carID string) ([]Lead, error)
+	UpdateLead(id string, patch LeadPatch, expectedStatus string) (*Lead, error)
+	AddLeadNote(note *LeadNote) error
+	GetLeadNotes(leadID string) ([]LeadNote, error)
 	CreateCar(c *Car) error
+	UpdateCar(id string, patch CarPatch, expectedVersion int) (*Car, error)
 	GetAllCars() ([]Car, error)
 	GetAvailableCars() ([]Car, error)
+	FindCars(f CarFilter) ([]Car, int, error)
+	SearchCars(terms []string, raw string, limit int) ([]CarSearchHit, error)
 	GetCarByID(id string) (*Car, error)
 	GetCarsInTransit() ([]Car, error)
-	UpdatePrice(id string, priceKZT float64) error
+	UpdatePrice(change *PriceChange) error
+	UpdatePrices(changes []PriceChange) ([]PriceChange, error)
+	UnlockPrice(carID string) error
+	GetPriceHistory(carID string) ([]PriceChange, error)
+	SaveExchangeRate(rate *ExchangeRate) error
+	GetExchangeRates(q RateQuery) ([]ExchangeRate, error)
+	GetLatestRates() (map[string]ExchangeRate, error)
+	GetPricingRules() ([]PricingRule, error)
+	CreatePricingRule(rule *PricingRule) error
+	UpdatePricingRule(rule *PricingRule) error
+	DeletePricingRule(id string) error
 	BookCar(carID string, userID string) error
+	GetCarsByUser(userID string) ([]Car, error)
+	WatchCar(userID string, carID string) error
+	UnwatchCar(userID string, carID string) error
+	GetWatchlist(userID string) ([]Car, error)
+	GetWatches() ([]Watch, error)
+	UpdateWatch(w Watch) error
 	DeleteCar(id string) error
-	UpdateStatus(id string, status string) error
+	UpdateStatus(change StatusChange) error
+	GetCarStatusHistory(carID string) ([]CarStatusEvent, error)
 }
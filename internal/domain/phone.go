@@ -0,0 +1,44 @@
+package domain
+
+import "strings"
+
+// NormalizePhone converts a customer-entered phone number to E.164 ("+77011234567").
+// Kazakhstan numbers may be written in any of the local forms: 8 701 123 45 67,
+// 7011234567 or +7 (701) 123-45-67. Other countries must include their "+" or "00"
+// prefix. It reports false when the input is not a plausible phone number.
+func NormalizePhone(raw string) (string, bool) {
+	s := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(strings.TrimSpace(raw))
+
+	international := false
+	switch {
+	case strings.HasPrefix(s, "+"):
+		s, international = s[1:], true
+	case strings.HasPrefix(s, "00"):
+		s, international = s[2:], true
+	}
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return "", false
+	}
+
+	if !international {
+		switch {
+		case len(s) == 11 && s[0] == '8':
+			s = "7" + s[1:]
+		case len(s) == 10 && s[0] == '7':
+			s = "7" + s
+		}
+		if len(s) != 11 || s[0] != '7' {
+			return "", false
+		}
+	}
+
+	// Country code 7 (Kazakhstan, Russia) always has ten digits after it;
+	// elsewhere E.164 allows up to 15 digits in total.
+	if s[0] == '7' && len(s) != 11 {
+		return "", false
+	}
+	if s[0] == '0' || len(s) < 8 || len(s) > 15 {
+		return "", false
+	}
+	return "+" + s, true
+}
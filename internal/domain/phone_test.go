@@ -0,0 +1,41 @@
+package domain
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	valid := []struct {
+		raw, want string
+	}{
+		{"8 701 123 45 67", "+77011234567"},
+		{"+7 (701) 123-45-67", "+77011234567"},
+		{"7011234567", "+77011234567"},
+		{"77011234567", "+77011234567"},
+		{" 8-701-123-45-67 ", "+77011234567"},
+		{"+49 30 1234567", "+49301234567"},
+		{"0049301234567", "+49301234567"},
+	}
+	for _, tc := range valid {
+		got, ok := NormalizePhone(tc.raw)
+		if !ok || got != tc.want {
+			t.Errorf("NormalizePhone(%q) = %q, %v; want %q, true", tc.raw, got, ok, tc.want)
+		}
+	}
+
+	for _, raw := range []string{"", "12345", "+7 701 12", "abc", "87011234567x", "+0123456789", "+4930", "+1234567890123456"} {
+		if got, ok := NormalizePhone(raw); ok {
+			t.Errorf("NormalizePhone(%q) = %q, true; want rejected", raw, got)
+		}
+	}
+}
+
+func TestNormalizePhoneSameNumber(t *testing.T) {
+	// Every way of writing the same number must normalize identically so that
+	// repeat inquiries are recognized.
+	forms := []string{"8 701 123 45 67", "+7 (701) 123-45-67", "7011234567", "+77011234567", "0077011234567"}
+	want, _ := NormalizePhone(forms[0])
+	for _, raw := range forms[1:] {
+		if got, _ := NormalizePhone(raw); got != want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
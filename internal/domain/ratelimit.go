@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRateLimited is matched by every *RateLimitError via errors.Is.
+var ErrRateLimited = errors.New("too many requests")
+
+// RateLimitError reports a request refused by a rate limit and when to retry.
+type RateLimitError struct {
+	Scope      string // what was limited, e.g. "ip" or "phone"
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many requests; retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
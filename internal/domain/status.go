@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Car lifecycle statuses, mirrored by the CHECK constraint on cars.status.
+const (
+	StatusTransit   = "transit"
+	StatusAvailable = "available"
+	StatusReserved  = "reserved"
+	StatusSold      = "sold"
+)
+
+// carStatusTransitions lists the statuses reachable from each status.
+// reserved → available cancels a reservation; sold → available records a return.
+var carStatusTransitions = map[string][]string{
+	StatusTransit:   {StatusAvailable, StatusReserved},
+	StatusAvailable: {StatusReserved},
+	StatusReserved:  {StatusSold, StatusAvailable},
+	StatusSold:      {StatusAvailable},
+}
+
+// ErrInvalidStatusTransition is matched by every *StatusTransitionError via errors.Is.
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+// StatusTransitionError explains a rejected car status or lead stage change.
+type StatusTransitionError struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Allowed []string `json:"allowed"`
+}
+
+func (e *StatusTransitionError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("cannot change status from %q to %q", e.From, e.To)
+	}
+	return fmt.Sprintf("cannot change status from %q to %q; allowed: %s", e.From, e.To, strings.Join(e.Allowed, ", "))
+}
+
+func (e *StatusTransitionError) Is(target error) bool {
+	return target == ErrInvalidStatusTransition
+}
+
+// AllowedStatusTransitions returns the statuses a car may move to from the given one.
+func AllowedStatusTransitions(from string) []string {
+	return append([]string(nil), carStatusTransitions[from]...)
+}
+
+// ValidateStatusTransition checks a status change against the car lifecycle.
+func ValidateStatusTransition(from, to string) error {
+	for _, next := range carStatusTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return &StatusTransitionError{From: from, To: to, Allowed: AllowedStatusTransitions(from)}
+}
@@ -0,0 +1,29 @@
+package exchange
+
+import (
+	"Assignment3ADP/internal/domain"
+	"fmt"
+)
+
+// Config selects and configures an exchange-rate provider.
+type Config struct {
+	Provider  string // "nbk" (default), "static" or "fake"
+	NBKURL    string
+	RatesFile string
+}
+
+// NewProvider builds the provider named by cfg.Provider.
+func NewProvider(cfg Config) (domain.ExchangeRateProvider, error) {
+	switch cfg.Provider {
+	case "", "nbk":
+		return NewNBKProvider(cfg.NBKURL), nil
+	case "static":
+		if cfg.RatesFile == "" {
+			return nil, fmt.Errorf("static exchange provider requires a rates file")
+		}
+		return NewStaticProviderFromFile(cfg.RatesFile)
+	case "fake":
+		return NewFakeProvider(), nil
+	}
+	return nil, fmt.Errorf("unknown exchange provider %q", cfg.Provider)
+}
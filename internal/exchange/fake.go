@@ -0,0 +1,80 @@
+package exchange
+
+import (
+	"Assignment3ADP/internal/domain"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeProvider is a controllable provider for tests and local development.
+// Set Err to simulate an outage; Calls counts FetchRate and FetchRates invocations.
+type FakeProvider struct {
+	mu    sync.Mutex
+	Rates map[string]float64
+	Err   error
+	Calls int
+}
+
+// NewFakeProvider returns a fake preloaded with plausible KZT rates.
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{Rates: map[string]float64{
+		"USD": 525.0,
+		"EUR": 570.0,
+		"RUB": 5.6,
+		"CNY": 72.0,
+		"KRW": 0.38,
+	}}
+}
+
+// SetRate changes the rate returned for currency.
+func (p *FakeProvider) SetRate(currency string, rate float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Rates[strings.ToUpper(currency)] = rate
+}
+
+func (p *FakeProvider) FetchRate(ctx context.Context, currency string) (*domain.ExchangeRate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Calls++
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	return p.rate(currency)
+}
+
+func (p *FakeProvider) FetchRates(ctx context.Context, currencies []string) (map[string]*domain.ExchangeRate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Calls++
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	rates := map[string]*domain.ExchangeRate{}
+	for _, currency := range currencies {
+		if rate, err := p.rate(currency); err == nil {
+			rates[rate.Currency] = rate
+		}
+	}
+	return rates, nil
+}
+
+// rate looks up a currency; the caller holds p.mu.
+func (p *FakeProvider) rate(currency string) (*domain.ExchangeRate, error) {
+	currency = strings.ToUpper(currency)
+	rate, ok := p.Rates[currency]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRateUnavailable, currency)
+	}
+	return &domain.ExchangeRate{
+		Currency:  currency,
+		Rate:      rate,
+		Source:    "fake",
+		FetchedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,114 @@
+// Package exchange implements domain.ExchangeRateProvider for the currency worker.
+package exchange
+
+import (
+	"Assignment3ADP/internal/domain"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultNBKURL is the National Bank of Kazakhstan daily rates feed.
+const DefaultNBKURL = "https://nationalbank.kz/rss/rates_all.xml"
+
+// NBKProvider reads official KZT rates from the National Bank of Kazakhstan RSS feed.
+type NBKProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewNBKProvider(url string) *NBKProvider {
+	if url == "" {
+		url = DefaultNBKURL
+	}
+	return &NBKProvider{
+		URL:    url,
+		Client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// nbkFeed mirrors the parts of the RSS document we use. Each item's title is the
+// currency code, description the KZT price and quant the number of units priced.
+type nbkFeed struct {
+	Items []struct {
+		Title       string `xml:"title"`
+		Description string `xml:"description"`
+		Quant       string `xml:"quant"`
+	} `xml:"channel>item"`
+}
+
+func (p *NBKProvider) FetchRate(ctx context.Context, currency string) (*domain.ExchangeRate, error) {
+	rates, err := p.FetchRates(ctx, []string{currency})
+	if err != nil {
+		return nil, err
+	}
+	rate, ok := rates[strings.ToUpper(currency)]
+	if !ok {
+		return nil, fmt.Errorf("nbk: %w: %s", domain.ErrRateUnavailable, currency)
+	}
+	return rate, nil
+}
+
+// FetchRates downloads the feed once and looks up every requested currency in it.
+func (p *NBKProvider) FetchRates(ctx context.Context, currencies []string) (map[string]*domain.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("nbk: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nbk: unexpected status %s", resp.Status)
+	}
+
+	var feed nbkFeed
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("nbk: parse feed: %w", err)
+	}
+
+	wanted := map[string]bool{}
+	for _, currency := range currencies {
+		wanted[strings.ToUpper(currency)] = true
+	}
+
+	now := time.Now()
+	rates := map[string]*domain.ExchangeRate{}
+	for _, item := range feed.Items {
+		currency := strings.TrimSpace(item.Title)
+		if !wanted[currency] {
+			continue
+		}
+
+		price, err := parseNumber(item.Description)
+		if err != nil {
+			return nil, fmt.Errorf("nbk: bad rate %q for %s: %w", item.Description, currency, err)
+		}
+		quant := 1.0
+		if item.Quant != "" {
+			if quant, err = parseNumber(item.Quant); err != nil || quant <= 0 {
+				return nil, fmt.Errorf("nbk: bad quant %q for %s", item.Quant, currency)
+			}
+		}
+
+		rates[currency] = &domain.ExchangeRate{
+			Currency:  currency,
+			Rate:      price / quant,
+			Source:    "nbk",
+			FetchedAt: now,
+		}
+	}
+	return rates, nil
+}
+
+func parseNumber(s string) (float64, error) {
+	return strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", "."), 64)
+}
@@ -0,0 +1,61 @@
+package exchange
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+const nbkSample = `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0"><channel>
+<item><title>USD</title><description>470,50</description><quant>1</quant></item>
+<item><title>EUR</title><description>510.20</description><quant>1</quant></item>
+<item><title>KRW</title><description>34.10</description><quant>100</quant></item>
+</channel></rss>`
+
+func TestNBKProviderFetchRates(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(nbkSample))
+	}))
+	defer srv.Close()
+
+	p := NewNBKProvider(srv.URL)
+	rates, err := p.FetchRates(context.Background(), []string{"USD", "EUR", "KRW", "CNY"})
+	if err != nil {
+		t.Fatalf("FetchRates: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("feed downloaded %d times, want 1", n)
+	}
+
+	want := map[string]float64{"USD": 470.5, "EUR": 510.2, "KRW": 0.341}
+	if len(rates) != len(want) {
+		t.Errorf("got rates for %d currencies, want %d: %v", len(rates), len(want), rates)
+	}
+	for currency, rate := range want {
+		got, ok := rates[currency]
+		if !ok {
+			t.Errorf("missing %s", currency)
+			continue
+		}
+		if math.Abs(got.Rate-rate) > 1e-9 {
+			t.Errorf("%s rate = %v, want %v", currency, got.Rate, rate)
+		}
+	}
+}
+
+func TestNBKProviderFetchRateUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(nbkSample))
+	}))
+	defer srv.Close()
+
+	if _, err := NewNBKProvider(srv.URL).FetchRate(context.Background(), "CNY"); err == nil {
+		t.Fatal("FetchRate(CNY) succeeded for a feed without CNY")
+	}
+}
@@ -0,0 +1,63 @@
+package exchange
+
+import (
+	"Assignment3ADP/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// StaticProvider serves fixed rates, e.g. from a JSON file, for offline use.
+type StaticProvider struct {
+	Rates  map[string]float64
+	Source string
+}
+
+// NewStaticProviderFromFile loads rates from a JSON object such as {"USD": 470.5, "EUR": 510}.
+func NewStaticProviderFromFile(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates map[string]float64
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	normalized := make(map[string]float64, len(rates))
+	for code, rate := range rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("%s: rate for %s must be positive", path, code)
+		}
+		normalized[strings.ToUpper(code)] = rate
+	}
+	return &StaticProvider{Rates: normalized, Source: "file:" + path}, nil
+}
+
+func (p *StaticProvider) FetchRate(ctx context.Context, currency string) (*domain.ExchangeRate, error) {
+	currency = strings.ToUpper(currency)
+	rate, ok := p.Rates[currency]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRateUnavailable, currency)
+	}
+	return &domain.ExchangeRate{
+		Currency:  currency,
+		Rate:      rate,
+		Source:    p.Source,
+		FetchedAt: time.Now(),
+	}, nil
+}
+
+func (p *StaticProvider) FetchRates(ctx context.Context, currencies []string) (map[string]*domain.ExchangeRate, error) {
+	rates := map[string]*domain.ExchangeRate{}
+	for _, currency := range currencies {
+		if rate, err := p.FetchRate(ctx, currency); err == nil {
+			rates[rate.Currency] = rate
+		}
+	}
+	return rates, nil
+}
@@ -17,7 +17,7 @@ func (h *Handler) GetAdminDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	leads, err := h.AdminService.Repo.GetAllLeads()
+	leads, err := h.LeadService.ListLeads()
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "DB Error")
 		return
@@ -1,12 +1,12 @@
 package handlers
 
 import (
+	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/middleware"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // Login authenticates a user and returns a JWT.
@@ -22,6 +22,10 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	user, err := h.AuthService.Login(creds.Username, creds.Password)
+	if errors.Is(err, domain.ErrUserDisabled) {
+		respondError(w, http.StatusForbidden, "Account is disabled")
+		return
+	}
 	if err != nil {
 		log.Printf("[DEBUG] Login failed for user '%s' (password length: %d): %v", creds.Username, len(creds.Password), err)
 		respondError(w, http.StatusUnauthorized, "Invalid credentials")
@@ -29,23 +33,14 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("[DEBUG] Login successful for user '%s'", creds.Username)
 
-	claims := jwt.MapClaims{
-		"username": user.Username,
-		"role":     user.Role,
-		"exp":      time.Now().Add(24 * time.Hour).Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(h.jwtKey)
+	tokens, err := h.AuthService.IssueTokens(user)
 	if err != nil {
+		log.Printf("Token issue failed for user '%s': %v", creds.Username, err)
 		respondError(w, http.StatusInternalServerError, "Could not generate token")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{
-		"token":   tokenString,
-		"expires": time.Now().Add(24 * time.Hour).Format(time.RFC3339),
-	})
+	respondJSON(w, http.StatusOK, tokens)
 }
 
 // Register creates a new user account.
@@ -76,3 +71,80 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		"message": "User registered successfully",
 	})
 }
+
+// RefreshToken exchanges a refresh token for a new access/refresh token pair.
+func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		respondError(w, http.StatusBadRequest, "refresh_token is required")
+		return
+	}
+
+	tokens, err := h.AuthService.RefreshTokens(req.RefreshToken)
+	switch {
+	case errors.Is(err, domain.ErrInvalidToken), errors.Is(err, domain.ErrTokenRevoked):
+		respondError(w, http.StatusUnauthorized, err.Error())
+		return
+	case errors.Is(err, domain.ErrUserDisabled):
+		respondError(w, http.StatusForbidden, "Account is disabled")
+		return
+	case err != nil:
+		log.Printf("Token refresh failed: %v", err)
+		respondError(w, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tokens)
+}
+
+// Logout revokes the caller's current access token and the supplied refresh token.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unknown user")
+		return
+	}
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	// The body is optional; a bare POST only revokes the access token.
+	json.NewDecoder(r.Body).Decode(&req)
+
+	err := h.AuthService.Logout(principal, req.RefreshToken)
+	if errors.Is(err, domain.ErrInvalidToken) {
+		respondError(w, http.StatusBadRequest, "Refresh token does not belong to this user")
+		return
+	}
+	if err != nil {
+		log.Printf("Logout failed for user '%s': %v", principal.Username, err)
+		respondError(w, http.StatusInternalServerError, "Could not log out")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "logged_out"})
+}
+
+// LogoutAllSessions revokes every session of the given user (admin action).
+func (h *Handler) LogoutAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUUID(w, r, "User not found")
+	if !ok {
+		return
+	}
+
+	err := h.AuthService.LogoutAllSessions(userID)
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		respondError(w, http.StatusNotFound, "User not found")
+		return
+	case err != nil:
+		log.Printf("Revoking sessions of user %s failed: %v", userID, err)
+		respondError(w, http.StatusInternalServerError, "Could not revoke sessions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "sessions_revoked"})
+}
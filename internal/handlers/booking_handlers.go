@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/middleware"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// BookCar reserves a car under the authenticated customer's account.
+func (h *Handler) BookCar(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unknown user")
+		return
+	}
+	userID := principal.UserID
+
+	carID, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	err := h.ClientService.BookTestDrive(carID, userID)
+	switch {
+	case errors.Is(err, domain.ErrCarNotFound):
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	case errors.Is(err, domain.ErrCarNotAvailable):
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		log.Printf("Booking failed for car %s (user %s): %v", carID, userID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to book car")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]string{
+		"status":  "reserved",
+		"message": "Car reserved successfully",
+	})
+}
+
+// GetMyBookings lists the cars reserved by the authenticated customer.
+func (h *Handler) GetMyBookings(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unknown user")
+		return
+	}
+	userID := principal.UserID
+
+	cars, err := h.ClientService.GetMyBookings(userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch bookings")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, toPublicCars(cars))
+}
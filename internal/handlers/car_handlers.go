@@ -2,40 +2,150 @@ package handlers
 
 import (
 	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/middleware"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 )
 
-// GetCatalog returns the list of available cars.
+// GetCatalog returns a page of available cars. Query parameters: make, model, status,
+// min_price and max_price (KZT), year, min_year, max_year, sort (created_at, price, year,
+// mileage, make), order (asc, desc), page and page_size. X-Total-Count carries the number
+// of matches. With ?currency= each car also carries its KZT price converted at the latest stored rate.
 func (h *Handler) GetCatalog(w http.ResponseWriter, r *http.Request) {
-	cars, err := h.ClientService.GetCatalog()
+	var rate *domain.ExchangeRate
+	currency := strings.ToUpper(r.URL.Query().Get("currency"))
+	if currency != "" && currency != domain.CurrencyKZT {
+		if !domain.IsSupportedCurrency(currency) {
+			respondError(w, http.StatusBadRequest, "Unsupported currency: "+currency)
+			return
+		}
+
+		var err error
+		rate, err = h.ClientService.GetLatestRate(currency)
+		switch {
+		case errors.Is(err, domain.ErrRateUnavailable):
+			respondError(w, http.StatusServiceUnavailable, "No exchange rate available for "+currency)
+			return
+		case err != nil:
+			log.Printf("Failed to load %s rate: %v", currency, err)
+			respondError(w, http.StatusInternalServerError, "Failed to fetch catalog")
+			return
+		}
+	}
+
+	filter, err := parseCarFilter(r.URL.Query())
 	if err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	cars, total, err := h.ClientService.GetCatalog(filter)
+	if errors.Is(err, domain.ErrValidation) {
+		respondValidationError(w, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to fetch catalog: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to fetch catalog")
 		return
 	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	w.Header().Set("X-Page", strconv.Itoa(filter.Page))
+	w.Header().Set("X-Page-Size", strconv.Itoa(filter.PageSize))
 
-	var safeCars []domain.PublicCar
-	for _, c := range cars {
-		safeCars = append(safeCars, domain.PublicCar{
-			ID:       c.ID,
-			Make:     c.Make,
-			Model:    c.Model,
-			PriceKZT: c.PriceKZT,
-			Status:   c.Status,
-			ImageURL: c.ImageURL,
+	public := toPublicCars(cars)
+	if currency != "" {
+		for i := range public {
+			public[i].Currency = currency
+			public[i].Price = public[i].PriceKZT
+			if rate != nil {
+				public[i].Price = math.Round(public[i].PriceKZT/rate.Rate*100) / 100
+			}
+		}
+	}
+
+	respondJSON(w, http.StatusOK, public)
+}
+
+// SearchCars ranks catalog cars against ?q= (make, model, VIN, description),
+// tolerating typos. Each result carries a highlight with matches in <mark> tags.
+func (h *Handler) SearchCars(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "limit must be an integer")
+			return
+		}
+		limit = n
+	}
+
+	hits, err := h.ClientService.SearchCars(r.URL.Query().Get("q"), limit)
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		respondValidationError(w, err)
+		return
+	case err != nil:
+		log.Printf("Search failed for %q: %v", r.URL.Query().Get("q"), err)
+		respondError(w, http.StatusInternalServerError, "Search failed")
+		return
+	}
+
+	type result struct {
+		domain.PublicCar
+		Rank      float64 `json:"rank"`
+		Highlight string  `json:"highlight"`
+	}
+	results := []result{}
+	for _, hit := range hits {
+		results = append(results, result{
+			PublicCar: toPublicCar(hit.Car),
+			Rank:      hit.Rank,
+			Highlight: hit.Headline,
 		})
 	}
 
-	respondJSON(w, http.StatusOK, safeCars)
+	respondJSON(w, http.StatusOK, results)
 }
 
-// GetCarDetails returns a single car by UUID.
-func (h *Handler) GetCarDetails(w http.ResponseWriter, r *http.Request) {
-	prefix := "/api/cars/"
-	id := r.URL.Path[len(prefix):]
+// toPublicCars strips internal fields (VIN, USD cost) before exposing cars to customers.
+func toPublicCars(cars []domain.Car) []domain.PublicCar {
+	safeCars := []domain.PublicCar{}
+	for _, c := range cars {
+		safeCars = append(safeCars, toPublicCar(c))
+	}
+	return safeCars
+}
 
-	if id == "" {
-		respondError(w, http.StatusBadRequest, "Missing car ID")
+func toPublicCar(c domain.Car) domain.PublicCar {
+	return domain.PublicCar{
+		ID:          c.ID,
+		Make:        c.Make,
+		Model:       c.Model,
+		Year:        c.Year,
+		Mileage:     c.Mileage,
+		Color:       c.Color,
+		Location:    c.Location,
+		Description: c.Description,
+		PriceKZT:    c.PriceKZT,
+		Status:      c.Status,
+		ImageURL:    c.ImageURL,
+	}
+}
+
+// GetCarDetails returns a single car by UUID, without the internal fields the
+// catalog also hides. Staff edit cars from GetAdminCar.
+func (h *Handler) GetCarDetails(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "Car not found")
+	if !ok {
 		return
 	}
 
@@ -45,16 +155,47 @@ func (h *Handler) GetCarDetails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	respondJSON(w, http.StatusOK, toPublicCar(*car))
+}
+
+// GetAdminCar returns the full record of a car for the edit form. The ETag
+// carries its version for the If-Match header of a following PATCH.
+func (h *Handler) GetAdminCar(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	car, err := h.AdminService.GetCar(id)
+	switch {
+	case errors.Is(err, domain.ErrCarNotFound):
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	case err != nil:
+		log.Printf("Failed to fetch car %s: %v", id, err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch car")
+		return
+	}
+
+	w.Header().Set("ETag", carETag(car))
 	respondJSON(w, http.StatusOK, car)
 }
 
 // CreateCar adds a new car to the inventory.
 func (h *Handler) CreateCar(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		VIN      string  `json:"vin"`
-		Model    string  `json:"model"`
-		ImageURL string  `json:"image_url"`
-		Price    float64 `json:"price"`
+		VIN         string  `json:"vin"`
+		Make        string  `json:"make"`
+		Model       string  `json:"model"`
+		Category    string  `json:"category"`
+		Year        int     `json:"year"`
+		Mileage     int     `json:"mileage"`
+		Color       string  `json:"color"`
+		Location    string  `json:"location"`
+		Description string  `json:"description"`
+		ImageURL    string  `json:"image_url"`
+		Price       float64 `json:"price"`
+		Currency    string  `json:"currency"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -62,29 +203,122 @@ func (h *Handler) CreateCar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.VIN == "" || req.Model == "" || req.Price <= 0 {
-		respondError(w, http.StatusBadRequest, "VIN, Model, and positive Price are required")
-		return
+	car := &domain.Car{
+		VIN:              req.VIN,
+		Make:             req.Make,
+		Model:            req.Model,
+		Category:         req.Category,
+		Year:             req.Year,
+		Mileage:          req.Mileage,
+		Color:            req.Color,
+		Location:         req.Location,
+		Description:      req.Description,
+		ImageURL:         req.ImageURL,
+		PurchasePrice:    req.Price,
+		PurchaseCurrency: req.Currency,
 	}
 
-	if err := h.AdminService.CreateCar(req.VIN, req.Model, req.ImageURL, req.Price); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	err := h.AdminService.CreateCar(car)
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		respondValidationError(w, err)
+		return
+	case errors.Is(err, domain.ErrDuplicateVIN):
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, domain.ErrRateUnavailable):
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		log.Printf("Failed to create car %s: %v", car.VIN, err)
+		respondError(w, http.StatusInternalServerError, "Failed to create car")
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, map[string]string{
 		"status":  "success",
 		"message": "Car added to inventory",
+		"id":      car.ID,
 	})
 }
 
+// UpdateCar applies a partial edit to a car. The caller must send the version it
+// last saw, either as an If-Match header (the ETag from GetAdminCar) or as
+// "version" in the body.
+func (h *Handler) UpdateCar(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		domain.CarPatch
+		Version *int `json:"version"`
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	version, ok := parseIfMatch(r.Header.Get("If-Match"))
+	if !ok && req.Version != nil {
+		version, ok = *req.Version, true
+	}
+	if !ok {
+		respondError(w, http.StatusPreconditionRequired, "If-Match header or version field is required")
+		return
+	}
+
+	car, err := h.AdminService.UpdateCar(id, req.CarPatch, version)
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		respondValidationError(w, err)
+		return
+	case errors.Is(err, domain.ErrCarNotFound):
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	case errors.Is(err, domain.ErrVersionConflict):
+		respondError(w, http.StatusPreconditionFailed, err.Error())
+		return
+	case errors.Is(err, domain.ErrDuplicateVIN):
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, domain.ErrRateUnavailable):
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		log.Printf("Failed to update car %s: %v", id, err)
+		respondError(w, http.StatusInternalServerError, "Failed to update car")
+		return
+	}
+
+	w.Header().Set("ETag", carETag(car))
+	respondJSON(w, http.StatusOK, car)
+}
+
+// carETag exposes the car's version as a strong entity tag.
+func carETag(c *domain.Car) string {
+	return `"` + strconv.Itoa(c.Version) + `"`
+}
+
+// parseIfMatch extracts the version from an If-Match value such as "3" or W/"3".
+func parseIfMatch(header string) (int, bool) {
+	value := strings.TrimPrefix(strings.TrimSpace(header), "W/")
+	value = strings.Trim(value, `"`)
+	if value == "" {
+		return 0, false
+	}
+	version, err := strconv.Atoi(value)
+	return version, err == nil
+}
+
 // DeleteCar handles vehicle removal.
 func (h *Handler) DeleteCar(w http.ResponseWriter, r *http.Request) {
-	prefix := "/api/admin/cars/"
-	id := r.URL.Path[len(prefix):]
-
-	if id == "" {
-		respondError(w, http.StatusBadRequest, "Missing car ID")
+	id, ok := pathUUID(w, r, "Car not found")
+	if !ok {
 		return
 	}
 
@@ -101,6 +335,7 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		ID     string `json:"id"`
 		Status string `json:"status"`
+		Reason string `json:"reason"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -108,10 +343,136 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.AdminService.UpdateStatus(req.ID, req.Status); err != nil {
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+
+	var transitionErr *domain.StatusTransitionError
+	err := h.AdminService.UpdateStatus(req.ID, req.Status, principal.UserID, req.Reason)
+	switch {
+	case errors.As(err, &transitionErr):
+		respondJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   transitionErr.Error(),
+			"from":    transitionErr.From,
+			"to":      transitionErr.To,
+			"allowed": transitionErr.Allowed,
+		})
+		return
+	case errors.Is(err, domain.ErrCarNotFound):
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	case err != nil:
+		log.Printf("Failed to update status of car %s: %v", req.ID, err)
 		respondError(w, http.StatusInternalServerError, "Failed to update status")
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"status": "updated"})
 }
+
+// GetCarHistory returns the status timeline of a car.
+func (h *Handler) GetCarHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	events, err := h.AdminService.GetStatusHistory(id)
+	if errors.Is(err, domain.ErrCarNotFound) {
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch history")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, events)
+}
+
+// DecodeVIN returns the attributes that can be derived from a VIN.
+func (h *Handler) DecodeVIN(w http.ResponseWriter, r *http.Request) {
+	decoded, err := h.AdminService.DecodeVIN(r.PathValue("vin"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, decoded)
+}
+
+// parseCarFilter reads catalog query parameters, reporting every malformed one.
+func parseCarFilter(q url.Values) (domain.CarFilter, error) {
+	v := &domain.ValidationError{}
+	f := domain.CarFilter{
+		Make:     strings.TrimSpace(q.Get("make")),
+		Model:    strings.TrimSpace(q.Get("model")),
+		Sort:     q.Get("sort"),
+		Page:     1,
+		PageSize: domain.DefaultPageSize,
+	}
+
+	if status := q.Get("status"); status != "" {
+		f.Statuses = strings.Split(status, ",")
+	}
+
+	number := func(name string) float64 {
+		raw := q.Get(name)
+		if raw == "" {
+			return 0
+		}
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil || n < 0 {
+			v.Add(name, "must be a non-negative number")
+			return 0
+		}
+		return n
+	}
+	integer := func(name string, min, max int) int {
+		raw := q.Get(name)
+		if raw == "" {
+			return 0
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < min || n > max {
+			v.Add(name, fmt.Sprintf("must be an integer between %d and %d", min, max))
+			return 0
+		}
+		return n
+	}
+
+	f.MinPriceKZT, f.MaxPriceKZT = number("min_price"), number("max_price")
+	if f.MaxPriceKZT > 0 && f.MinPriceKZT > f.MaxPriceKZT {
+		v.Add("max_price", "must not be below min_price")
+	}
+
+	f.MinYear, f.MaxYear = integer("min_year", 1900, 9999), integer("max_year", 1900, 9999)
+	if year := integer("year", 1900, 9999); year > 0 {
+		f.MinYear, f.MaxYear = year, year
+	}
+	if f.MaxYear > 0 && f.MinYear > f.MaxYear {
+		v.Add("max_year", "must not be below min_year")
+	}
+
+	if f.Sort == "" {
+		f.Sort, f.Desc = domain.SortNewest, true
+	} else if !slices.Contains(domain.CarSortFields, f.Sort) {
+		v.Add("sort", "must be one of "+strings.Join(domain.CarSortFields, ", "))
+	}
+	switch strings.ToLower(q.Get("order")) {
+	case "":
+	case "asc":
+		f.Desc = false
+	case "desc":
+		f.Desc = true
+	default:
+		v.Add("order", "must be asc or desc")
+	}
+
+	if page := integer("page", 1, math.MaxInt32); page > 0 {
+		f.Page = page
+	}
+	if size := integer("page_size", 1, domain.MaxPageSize); size > 0 {
+		f.PageSize = size
+	}
+
+	return f, v.OrNil()
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"Assignment3ADP/internal/domain"
 	"Assignment3ADP/internal/middleware"
 	"Assignment3ADP/internal/service"
 	"encoding/json"
+	"errors"
+	"expvar"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -13,15 +16,17 @@ type Handler struct {
 	AuthService   *service.AuthService
 	AdminService  *service.AdminService
 	ClientService *service.ClientService
-	jwtKey        []byte
+	LeadService   *service.LeadService
+	authenticator *middleware.Authenticator
 }
 
-func NewHandler(auth *service.AuthService, admin *service.AdminService, client *service.ClientService) *Handler {
+func NewHandler(auth *service.AuthService, admin *service.AdminService, client *service.ClientService, leads *service.LeadService) *Handler {
 	return &Handler{
 		AuthService:   auth,
 		AdminService:  admin,
 		ClientService: client,
-		jwtKey:        []byte(os.Getenv("JWT_SECRET")),
+		LeadService:   leads,
+		authenticator: middleware.NewAuthenticator(auth),
 	}
 }
 
@@ -31,17 +36,72 @@ func (h *Handler) SetupRoutes() *http.ServeMux {
 
 	// Public Routes
 	mux.HandleFunc("GET /api/cars", h.GetCatalog)
-	mux.HandleFunc("GET /api/cars/", h.GetCarDetails) // Matches /api/cars/{id}
+	mux.HandleFunc("GET /api/cars/search", h.SearchCars)
+	mux.HandleFunc("GET /api/cars/{id}", h.GetCarDetails)
 	mux.HandleFunc("POST /api/login", h.Login)
 	mux.HandleFunc("POST /api/register", h.Register)
+	mux.HandleFunc("GET /api/leads/token", h.GetLeadFormToken)
 	mux.HandleFunc("POST /api/leads", h.CreateLead)
+	mux.HandleFunc("GET /api/rates", h.GetRates)
+	mux.HandleFunc("POST /api/token/refresh", h.RefreshToken)
+
+	authenticated := h.authenticator.AuthMiddleware
+
+	// protect chains authentication with a permission check from the RBAC matrix.
+	protect := func(perm middleware.Permission, next http.HandlerFunc) http.HandlerFunc {
+		return authenticated(middleware.RequirePermission(perm)(next))
+	}
+
+	// Session Routes (Any authenticated user)
+	mux.HandleFunc("POST /api/logout", authenticated(h.Logout))
+	mux.HandleFunc("PUT /api/me/email", authenticated(h.SetMyEmail))
+
+	// Customer Routes (User)
+	mux.HandleFunc("POST /api/cars/{id}/book", protect(middleware.PermBookCars, h.BookCar))
+	mux.HandleFunc("GET /api/me/bookings", protect(middleware.PermBookCars, h.GetMyBookings))
+	mux.HandleFunc("POST /api/cars/{id}/watch", protect(middleware.PermWatchCars, h.WatchCar))
+	mux.HandleFunc("DELETE /api/cars/{id}/watch", protect(middleware.PermWatchCars, h.UnwatchCar))
+	mux.HandleFunc("GET /api/me/watchlist", protect(middleware.PermWatchCars, h.GetMyWatchlist))
 
 	// Protected Routes (Admin/Manager)
-	mux.HandleFunc("GET /api/admin/dashboard", middleware.AuthMiddleware(h.GetAdminDashboard))
-	mux.HandleFunc("POST /api/admin/cars", middleware.AuthMiddleware(h.CreateCar))
-	mux.HandleFunc("POST /api/admin/upload", middleware.AuthMiddleware(h.UploadImage))
-	mux.HandleFunc("DELETE /api/admin/cars/", middleware.AuthMiddleware(h.DeleteCar))
-	mux.HandleFunc("PUT /api/admin/cars/status", middleware.AuthMiddleware(h.UpdateStatus))
+	mux.HandleFunc("GET /api/admin/dashboard", protect(middleware.PermViewDashboard, h.GetAdminDashboard))
+	mux.HandleFunc("GET /api/admin/metrics", protect(middleware.PermViewDashboard, expvar.Handler().ServeHTTP))
+	mux.HandleFunc("POST /api/admin/cars", protect(middleware.PermCreateCars, h.CreateCar))
+	mux.HandleFunc("POST /api/admin/upload", protect(middleware.PermUploadImages, h.UploadImage))
+	mux.HandleFunc("GET /api/admin/vin/{vin}/decode", protect(middleware.PermCreateCars, h.DecodeVIN))
+	mux.HandleFunc("GET /api/admin/cars/{id}", protect(middleware.PermEditCars, h.GetAdminCar))
+	mux.HandleFunc("PATCH /api/admin/cars/{id}", protect(middleware.PermEditCars, h.UpdateCar))
+	mux.HandleFunc("DELETE /api/admin/cars/{id}", protect(middleware.PermDeleteCars, h.DeleteCar))
+	mux.HandleFunc("PUT /api/admin/cars/status", protect(middleware.PermUpdateStatus, h.UpdateStatus))
+	mux.HandleFunc("GET /api/admin/cars/{id}/history", protect(middleware.PermViewDashboard, h.GetCarHistory))
+	mux.HandleFunc("GET /api/admin/cars/{id}/prices", protect(middleware.PermViewDashboard, h.GetCarPriceHistory))
+	mux.HandleFunc("PUT /api/admin/cars/{id}/price", protect(middleware.PermManagePricing, h.OverrideCarPrice))
+	mux.HandleFunc("DELETE /api/admin/cars/{id}/price", protect(middleware.PermManagePricing, h.UnlockCarPrice))
+	mux.HandleFunc("GET /api/admin/cars/{id}/price-preview", protect(middleware.PermViewDashboard, h.PreviewCarPrice))
+	mux.HandleFunc("GET /api/admin/cars/{id}/leads", protect(middleware.PermManageLeads, h.GetCarLeads))
+
+	// Pricing Rules (Admin)
+	mux.HandleFunc("GET /api/admin/pricing-rules", protect(middleware.PermManagePricing, h.ListPricingRules))
+	mux.HandleFunc("POST /api/admin/pricing-rules", protect(middleware.PermManagePricing, h.CreatePricingRule))
+	mux.HandleFunc("PUT /api/admin/pricing-rules/{id}", protect(middleware.PermManagePricing, h.UpdatePricingRule))
+	mux.HandleFunc("DELETE /api/admin/pricing-rules/{id}", protect(middleware.PermManagePricing, h.DeletePricingRule))
+
+	// Lead Pipeline
+	mux.HandleFunc("GET /api/admin/leads/{id}", protect(middleware.PermManageLeads, h.GetLead))
+	mux.HandleFunc("PATCH /api/admin/leads/{id}", protect(middleware.PermManageLeads, h.UpdateLead))
+	mux.HandleFunc("POST /api/admin/leads/{id}/notes", protect(middleware.PermManageLeads, h.AddLeadNote))
+
+	// Background Jobs
+	mux.HandleFunc("GET /api/admin/jobs/currency", protect(middleware.PermViewDashboard, h.GetCurrencyJobStatus))
+	mux.HandleFunc("POST /api/admin/jobs/currency/run", protect(middleware.PermManagePricing, h.RunCurrencyJob))
+
+	// User Management (Admin)
+	mux.HandleFunc("GET /api/admin/users", protect(middleware.PermManageUsers, h.ListUsers))
+	mux.HandleFunc("POST /api/admin/users", protect(middleware.PermManageUsers, h.CreateUser))
+	mux.HandleFunc("PUT /api/admin/users/{id}/role", protect(middleware.PermManageUsers, h.ChangeUserRole))
+	mux.HandleFunc("PUT /api/admin/users/{id}/disabled", protect(middleware.PermManageUsers, h.SetUserDisabled))
+	mux.HandleFunc("PUT /api/admin/users/{id}/password", protect(middleware.PermManageUsers, h.ResetUserPassword))
+	mux.HandleFunc("POST /api/admin/users/{id}/logout", protect(middleware.PermManageUsers, h.LogoutAllSessions))
 
 	// Static Files for Uploads
 	fs := http.FileServer(http.Dir("uploads"))
@@ -74,3 +134,27 @@ func respondJSON(w http.ResponseWriter, status int, payload interface{}) {
 func respondError(w http.ResponseWriter, status int, message string) {
 	respondJSON(w, status, map[string]string{"error": message})
 }
+
+// pathUUID reads the {id} path value, answering 404 with notFound when it is not
+// a UUID: no row can match it, and Postgres would fail the cast with a 500.
+func pathUUID(w http.ResponseWriter, r *http.Request, notFound string) (string, bool) {
+	id := r.PathValue("id")
+	if !domain.IsUUID(id) {
+		respondError(w, http.StatusNotFound, notFound)
+		return "", false
+	}
+	return id, true
+}
+
+// respondValidationError reports field-level input problems.
+func respondValidationError(w http.ResponseWriter, err error) {
+	var v *domain.ValidationError
+	if !errors.As(err, &v) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error":  "Validation failed",
+		"fields": v.Fields,
+	})
+}
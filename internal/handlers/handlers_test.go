@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"Assignment3ADP/internal/domain"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPathUUID(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cars/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathUUID(w, r, "Car not found")
+		if !ok {
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]string{"id": id})
+	})
+
+	cases := []struct {
+		id   string
+		want int
+	}{
+		{"0b7c6a2e-3f4d-4e5a-9b8c-1d2e3f4a5b6c", http.StatusOK},
+		{"0B7C6A2E-3F4D-4E5A-9B8C-1D2E3F4A5B6C", http.StatusOK},
+		{"not-a-uuid", http.StatusNotFound},
+		{"0b7c6a2e3f4d4e5a9b8c1d2e3f4a5b6c", http.StatusNotFound},
+		{"0b7c6a2e-3f4d-4e5a-9b8c-1d2e3f4a5b6", http.StatusNotFound},
+		{"0b7c6a2e-3f4d-4e5a-9b8c-1d2e3f4a5b6g", http.StatusNotFound},
+		{"1%27%20OR%201=1", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cars/"+tc.id, nil))
+		if rec.Code != tc.want {
+			t.Errorf("GET /cars/%s = %d, want %d", tc.id, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestGetCarDetailsMalformedID(t *testing.T) {
+	// The services are nil: a malformed ID must be answered before reaching them.
+	mux := NewHandler(nil, nil, nil, nil).SetupRoutes()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cars/abc", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /api/cars/abc = %d, want 404", rec.Code)
+	}
+}
+
+func TestToPublicCarHidesInternalFields(t *testing.T) {
+	until := time.Now().Add(time.Hour)
+	car := domain.Car{
+		ID:               "0b7c6a2e-3f4d-4e5a-9b8c-1d2e3f4a5b6c",
+		VIN:              "1HGCM82633A004352",
+		Make:             "Honda",
+		Model:            "Accord",
+		PriceUSD:         20000,
+		PriceKZT:         10000000,
+		PurchasePrice:    18000,
+		PurchaseCurrency: "EUR",
+		PriceLocked:      true,
+		PriceLockedUntil: &until,
+		StatusChangedBy:  "5f1e2d3c-4b5a-4968-8776-a5b4c3d2e1f0",
+		Version:          7,
+		Status:           domain.StatusAvailable,
+	}
+
+	body, err := json.Marshal(toPublicCar(car))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, hidden := range []string{"vin", "price_usd", "purchase_price", "purchase_currency", "status_changed_by", "price_locked", "price_locked_until", "version", "user_id"} {
+		if _, ok := fields[hidden]; ok {
+			t.Errorf("public car exposes %q: %s", hidden, body)
+		}
+	}
+	if fields["price_kzt"] != float64(10000000) {
+		t.Errorf("public car price_kzt = %v", fields["price_kzt"])
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"Assignment3ADP/internal/domain"
+	"errors"
+	"net/http"
+)
+
+// GetCurrencyJobStatus reports the currency worker's last run, its outcome and the next scheduled run.
+func (h *Handler) GetCurrencyJobStatus(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.AdminService.CurrencyJobStatus())
+}
+
+// RunCurrencyJob starts an out-of-schedule currency update, e.g. after a failed fetch.
+func (h *Handler) RunCurrencyJob(w http.ResponseWriter, r *http.Request) {
+	if err := h.AdminService.TriggerCurrencyJob(); errors.Is(err, domain.ErrJobRunning) {
+		respondError(w, http.StatusConflict, "Currency update is already running")
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, h.AdminService.CurrencyJobStatus())
+}
@@ -2,16 +2,63 @@ package handlers
 
 import (
 	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/middleware"
+	"Assignment3ADP/internal/service"
 	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
-// CreateLead handles customer inquiries.
+// clientIP returns the caller's address. X-Forwarded-For is only trusted when
+// the request arrives from a reverse proxy on a loopback or private address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer := net.ParseIP(host)
+	if peer == nil || !(peer.IsLoopback() || peer.IsPrivate()) {
+		return host
+	}
+	forwarded := r.Header.Get("X-Forwarded-For")
+	if forwarded == "" {
+		return host
+	}
+	// The proxy appends the address it saw, so the last entry is the trustworthy one.
+	hops := strings.Split(forwarded, ",")
+	if ip := net.ParseIP(strings.TrimSpace(hops[len(hops)-1])); ip != nil {
+		return ip.String()
+	}
+	return host
+}
+
+// GetLeadFormToken issues the signed token the public lead form must submit.
+func (h *Handler) GetLeadFormToken(w http.ResponseWriter, r *http.Request) {
+	token, expiresAt := h.LeadService.IssueFormToken()
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"token":      token,
+		"expires_at": expiresAt,
+	})
+}
+
+// CreateLead handles customer inquiries. inquiry_type defaults to test_drive;
+// each type requires its own details.
 func (h *Handler) CreateLead(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		CarModel string `json:"car_model"`
-		Name     string `json:"name"`
-		Phone    string `json:"phone"`
+		CarID       string                `json:"car_id"`
+		CarModel    string                `json:"car_model"`
+		Name        string                `json:"name"`
+		Phone       string                `json:"phone"`
+		InquiryType string                `json:"inquiry_type"`
+		Details     domain.InquiryDetails `json:"details"`
+		FormToken   string                `json:"form_token"`
+		Website     string                `json:"website"` // honeypot
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -20,19 +67,157 @@ func (h *Handler) CreateLead(w http.ResponseWriter, r *http.Request) {
 	}
 
 	lead := &domain.Lead{
+		CarID:         req.CarID,
 		CarModel:      req.CarModel,
 		CustomerName:  req.Name,
 		CustomerPhone: req.Phone,
-		InquiryType:   "test_drive",
+		InquiryType:   req.InquiryType,
+		Details:       req.Details,
 	}
 
-	if err := h.AdminService.Repo.CreateLead(lead); err != nil {
-		respondError(w, http.StatusInternalServerError, "Database error")
+	merged, err := h.LeadService.CreateLead(lead, service.LeadSource{
+		IP:        clientIP(r),
+		FormToken: req.FormToken,
+		Honeypot:  req.Website,
+	})
+	var rateErr *domain.RateLimitError
+	switch {
+	case errors.Is(err, domain.ErrSpamDetected):
+		// Answer bots as if the inquiry went through so they don't adapt; it is
+		// only counted in the metrics.
+		respondJSON(w, http.StatusCreated, map[string]string{
+			"status":  "success",
+			"message": "Inquiry received",
+		})
+		return
+	case errors.Is(err, domain.ErrInvalidFormToken):
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.As(err, &rateErr):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateErr.RetryAfter.Seconds()))))
+		respondError(w, http.StatusTooManyRequests, rateErr.Error())
+		return
+	case err != nil:
+		respondLeadError(w, err)
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, map[string]string{
+	// A repeat inquiry is folded into the customer's open lead.
+	status := http.StatusCreated
+	if merged {
+		status = http.StatusOK
+	}
+	respondJSON(w, status, map[string]string{
 		"status":  "success",
 		"message": "Inquiry received",
 	})
 }
+
+// GetCarLeads lists the customers who asked about a car.
+func (h *Handler) GetCarLeads(w http.ResponseWriter, r *http.Request) {
+	carID, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	leads, err := h.LeadService.GetCarLeads(carID)
+	if err != nil {
+		respondLeadError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, leads)
+}
+
+// GetLead returns a lead with its notes.
+func (h *Handler) GetLead(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "Lead not found")
+	if !ok {
+		return
+	}
+
+	lead, err := h.LeadService.GetLead(id)
+	if err != nil {
+		respondLeadError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lead)
+}
+
+// UpdateLead moves a lead through the pipeline and/or assigns a salesperson.
+// Send "assigned_user_id": "" to unassign.
+func (h *Handler) UpdateLead(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "Lead not found")
+	if !ok {
+		return
+	}
+
+	var patch domain.LeadPatch
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patch); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	lead, err := h.LeadService.UpdateLead(id, patch)
+	if err != nil {
+		respondLeadError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lead)
+}
+
+// AddLeadNote records a note, e.g. the outcome of a call, on a lead.
+func (h *Handler) AddLeadNote(w http.ResponseWriter, r *http.Request) {
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	id, ok := pathUUID(w, r, "Lead not found")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	note, err := h.LeadService.AddNote(id, principal.UserID, req.Body)
+	if err != nil {
+		respondLeadError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, note)
+}
+
+// respondLeadError maps lead pipeline errors to HTTP statuses.
+func respondLeadError(w http.ResponseWriter, err error) {
+	var transitionErr *domain.StatusTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		respondJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   transitionErr.Error(),
+			"from":    transitionErr.From,
+			"to":      transitionErr.To,
+			"allowed": transitionErr.Allowed,
+		})
+	case errors.Is(err, domain.ErrValidation):
+		respondValidationError(w, err)
+	case errors.Is(err, domain.ErrLeadNotFound):
+		respondError(w, http.StatusNotFound, "Lead not found")
+	case errors.Is(err, domain.ErrCarNotFound):
+		respondError(w, http.StatusNotFound, "Car not found")
+	case errors.Is(err, domain.ErrInvalidAssignee):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrLeadChanged):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Lead operation failed: %v", err)
+		respondError(w, http.StatusInternalServerError, "Database error")
+	}
+}
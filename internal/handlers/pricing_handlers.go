@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"Assignment3ADP/internal/domain"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// ListPricingRules returns every configured pricing rule.
+func (h *Handler) ListPricingRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.AdminService.ListPricingRules()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch pricing rules")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rules)
+}
+
+// CreatePricingRule adds a rule for a make, a category, or both.
+func (h *Handler) CreatePricingRule(w http.ResponseWriter, r *http.Request) {
+	var rule domain.PricingRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	rule.ID = ""
+
+	if err := h.AdminService.CreatePricingRule(&rule); err != nil {
+		respondPricingRuleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, rule)
+}
+
+// UpdatePricingRule replaces a rule with the request body.
+func (h *Handler) UpdatePricingRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := pricingRuleID(w, r)
+	if !ok {
+		return
+	}
+
+	var rule domain.PricingRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	rule.ID = id
+
+	if err := h.AdminService.UpdatePricingRule(&rule); err != nil {
+		respondPricingRuleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rule)
+}
+
+// DeletePricingRule removes a rule.
+func (h *Handler) DeletePricingRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := pricingRuleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.AdminService.DeletePricingRule(id); err != nil {
+		respondPricingRuleError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// PreviewCarPrice shows the price breakdown the worker would apply to a car today.
+func (h *Handler) PreviewCarPrice(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	preview, err := h.AdminService.PreviewPrice(id)
+	switch {
+	case errors.Is(err, domain.ErrCarNotFound):
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	case errors.Is(err, domain.ErrRateUnavailable):
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		log.Printf("Failed to preview price for car %s: %v", id, err)
+		respondError(w, http.StatusInternalServerError, "Failed to preview price")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, preview)
+}
+
+// pricingRuleID reads the numeric rule ID from the path, answering 404 when it is malformed.
+func pricingRuleID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		respondError(w, http.StatusNotFound, domain.ErrRuleNotFound.Error())
+		return "", false
+	}
+	return id, true
+}
+
+// respondPricingRuleError maps pricing rule errors to HTTP statuses.
+func respondPricingRuleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		respondValidationError(w, err)
+	case errors.Is(err, domain.ErrRuleNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrDuplicateRule):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Pricing rule operation failed: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to save pricing rule")
+	}
+}
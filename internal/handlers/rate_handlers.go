@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/middleware"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRatesLimit = 100
+	maxRatesLimit     = 1000
+)
+
+// GetRates returns stored exchange rates. Query parameters:
+// currency (e.g. USD), from and to (YYYY-MM-DD or RFC3339; a bare "to" date is inclusive) and limit.
+func (h *Handler) GetRates(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	q := domain.RateQuery{
+		Currency: strings.ToUpper(params.Get("currency")),
+		Limit:    defaultRatesLimit,
+	}
+
+	var err error
+	if q.From, err = parseTimeParam(params.Get("from"), false); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid 'from': "+err.Error())
+		return
+	}
+	if q.To, err = parseTimeParam(params.Get("to"), true); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid 'to': "+err.Error())
+		return
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		respondError(w, http.StatusBadRequest, "'from' must be before 'to'")
+		return
+	}
+
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxRatesLimit {
+			respondError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+		q.Limit = limit
+	}
+
+	rates, err := h.ClientService.GetExchangeRates(q)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch exchange rates")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rates)
+}
+
+// GetCarPriceHistory lists a car's KZT price changes and the rates that produced them.
+func (h *Handler) GetCarPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	changes, err := h.AdminService.GetPriceHistory(id)
+	if errors.Is(err, domain.ErrCarNotFound) {
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch price history")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, changes)
+}
+
+// OverrideCarPrice sets a manual KZT price that the currency worker will not overwrite.
+// Without locked_until the lock holds until DELETE /api/admin/cars/{id}/price.
+func (h *Handler) OverrideCarPrice(w http.ResponseWriter, r *http.Request) {
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	id, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		PriceKZT    float64    `json:"price_kzt"`
+		LockedUntil *time.Time `json:"locked_until"`
+		Reason      string     `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	change, err := h.AdminService.OverridePrice(id, req.PriceKZT, req.LockedUntil, req.Reason, principal.UserID)
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		respondValidationError(w, err)
+		return
+	case errors.Is(err, domain.ErrCarNotFound):
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	case err != nil:
+		log.Printf("Failed to override price of car %s: %v", id, err)
+		respondError(w, http.StatusInternalServerError, "Failed to update price")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"change":       change,
+		"price_locked": true,
+		"locked_until": req.LockedUntil,
+	})
+}
+
+// UnlockCarPrice releases a manual price override.
+func (h *Handler) UnlockCarPrice(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	err := h.AdminService.UnlockPrice(id)
+	if errors.Is(err, domain.ErrCarNotFound) {
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to unlock price")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "unlocked"})
+}
+
+// parseTimeParam accepts RFC3339 timestamps or YYYY-MM-DD dates. With endOfDay,
+// a bare date is turned into the exclusive bound at the start of the next day.
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, errors.New("expected YYYY-MM-DD or RFC3339")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
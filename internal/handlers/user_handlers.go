@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/middleware"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// ListUsers returns all user accounts.
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.AuthService.ListUsers()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DB Error")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, users)
+}
+
+// CreateUser lets an admin create an account with an explicit role.
+func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if req.Username == "" {
+		respondError(w, http.StatusBadRequest, "Username is required")
+		return
+	}
+
+	user, err := h.AuthService.CreateUser(req.Username, req.Password, req.Role)
+	if err != nil {
+		respondUserError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, user)
+}
+
+// ChangeUserRole promotes or demotes a user.
+func (h *Handler) ChangeUserRole(w http.ResponseWriter, r *http.Request) {
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	userID, ok := pathUUID(w, r, "User not found")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Role string `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := h.AuthService.ChangeRole(principal.UserID, userID, req.Role); err != nil {
+		respondUserError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
+// SetUserDisabled disables or re-enables a user account.
+func (h *Handler) SetUserDisabled(w http.ResponseWriter, r *http.Request) {
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	userID, ok := pathUUID(w, r, "User not found")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Disabled *bool `json:"disabled"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Disabled == nil {
+		respondError(w, http.StatusBadRequest, "Field 'disabled' (boolean) is required")
+		return
+	}
+
+	if err := h.AuthService.SetDisabled(principal.UserID, userID, *req.Disabled); err != nil {
+		respondUserError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
+// ResetUserPassword sets a new password for a user.
+func (h *Handler) ResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUUID(w, r, "User not found")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := h.AuthService.ResetPassword(userID, req.Password); err != nil {
+		respondUserError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
+// respondUserError maps user-management domain errors to HTTP statuses.
+func respondUserError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		respondError(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, domain.ErrUsernameTaken):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrInvalidRole), errors.Is(err, domain.ErrWeakPassword), errors.Is(err, domain.ErrSelfLockout):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("User management error: %v", err)
+		respondError(w, http.StatusInternalServerError, "DB Error")
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/middleware"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// WatchCar adds a car to the authenticated customer's watchlist.
+func (h *Handler) WatchCar(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unknown user")
+		return
+	}
+
+	carID, ok := pathUUID(w, r, "Car not found")
+	if !ok {
+		return
+	}
+
+	err := h.ClientService.WatchCar(principal.UserID, carID)
+	switch {
+	case errors.Is(err, domain.ErrCarNotFound):
+		respondError(w, http.StatusNotFound, "Car not found")
+		return
+	case err != nil:
+		log.Printf("Watch failed for car %s (user %s): %v", carID, principal.UserID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to watch car")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]string{"status": "watching"})
+}
+
+// UnwatchCar removes a car from the authenticated customer's watchlist.
+func (h *Handler) UnwatchCar(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unknown user")
+		return
+	}
+
+	carID, ok := pathUUID(w, r, domain.ErrNotWatching.Error())
+	if !ok {
+		return
+	}
+
+	err := h.ClientService.UnwatchCar(principal.UserID, carID)
+	switch {
+	case errors.Is(err, domain.ErrNotWatching):
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		respondError(w, http.StatusInternalServerError, "Failed to unwatch car")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "unwatched"})
+}
+
+// GetMyWatchlist lists the cars the authenticated customer is watching.
+func (h *Handler) GetMyWatchlist(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unknown user")
+		return
+	}
+
+	cars, err := h.ClientService.GetWatchlist(principal.UserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch watchlist")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, toPublicCars(cars))
+}
+
+// SetMyEmail sets the address watchlist alerts are sent to. An empty email removes it.
+func (h *Handler) SetMyEmail(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unknown user")
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	err := h.AuthService.SetEmail(principal.UserID, req.Email)
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		respondValidationError(w, err)
+		return
+	case err != nil:
+		respondUserError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
@@ -1,70 +1,58 @@
 package middleware
 
 import (
-	"encoding/json"
-	"fmt"
+	"Assignment3ADP/internal/domain"
+	"errors"
 	"log"
 	"net/http"
-	"os"
 	"strings"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
-// AuthMiddleware intercepts requests to ensure the user is logged in via JWT Header.
-func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		log.Println("[WARNING] JWT_SECRET is empty in AuthMiddleware!")
-	}
-	jwtKey := []byte(secret)
+// TokenVerifier validates an access token and resolves the caller behind it.
+type TokenVerifier interface {
+	VerifyAccessToken(tokenString string) (*domain.Principal, error)
+}
 
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+// Authenticator checks Bearer tokens, including the server-side revocation list.
+type Authenticator struct {
+	verifier TokenVerifier
+}
+
+func NewAuthenticator(verifier TokenVerifier) *Authenticator {
+	return &Authenticator{verifier: verifier}
+}
 
+// AuthMiddleware intercepts requests to ensure the user is logged in via JWT Header.
+// Authorization is left to RequireRole / RequirePermission.
+func (a *Authenticator) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Missing Authorization header"})
+			writeError(w, http.StatusUnauthorized, "Missing Authorization header")
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid Authorization header format"})
+			writeError(w, http.StatusUnauthorized, "Invalid Authorization header format")
 			return
 		}
-		tokenString := parts[1]
-
-		claims := jwt.MapClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return jwtKey, nil
-		})
 
-		if err != nil || !token.Valid {
-			log.Printf("[DEBUG] JWT Validation failed: %v", err)
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid or expired token"})
+		principal, err := a.verifier.VerifyAccessToken(parts[1])
+		switch {
+		case errors.Is(err, domain.ErrTokenRevoked):
+			writeError(w, http.StatusUnauthorized, "Token has been revoked")
 			return
-		}
-
-		role, ok := claims["role"].(string)
-		if !ok {
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid token claims"})
+		case errors.Is(err, domain.ErrInvalidToken):
+			log.Printf("[DEBUG] JWT Validation failed: %v", err)
+			writeError(w, http.StatusUnauthorized, "Invalid or expired token")
 			return
-		}
-
-		if role != "admin" && role != "manager" {
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Insufficient permissions"})
+		case err != nil:
+			log.Printf("[Auth Error] Token verification failed: %v", err)
+			writeError(w, http.StatusInternalServerError, "Could not verify token")
 			return
 		}
 
-		next(w, r)
+		next(w, r.WithContext(WithPrincipal(r.Context(), principal)))
 	}
 }
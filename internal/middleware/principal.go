@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"Assignment3ADP/internal/domain"
+	"context"
+)
+
+type contextKey string
+
+const principalKey contextKey = "principal"
+
+// WithPrincipal stores the caller in the context.
+func WithPrincipal(ctx context.Context, p *domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// PrincipalFromContext returns the caller placed into the context by the auth middleware.
+func PrincipalFromContext(ctx context.Context) (*domain.Principal, bool) {
+	p, ok := ctx.Value(principalKey).(*domain.Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Permission names a single action a role may perform.
+type Permission string
+
+const (
+	PermViewDashboard Permission = "dashboard:view"
+	PermCreateCars    Permission = "cars:create"
+	PermEditCars      Permission = "cars:edit"
+	PermUpdateStatus  Permission = "cars:update_status"
+	PermDeleteCars    Permission = "cars:delete"
+	PermUploadImages  Permission = "uploads:create"
+	PermManageLeads   Permission = "leads:manage"
+	PermManageUsers   Permission = "users:manage"
+	PermManagePricing Permission = "pricing:manage"
+	PermBookCars      Permission = "cars:book"
+	PermWatchCars     Permission = "cars:watch"
+)
+
+// rolePermissions is the permission matrix. Admins are granted everything.
+var rolePermissions = map[string][]Permission{
+	"manager": {
+		PermViewDashboard,
+		PermCreateCars,
+		PermEditCars,
+		PermUpdateStatus,
+		PermUploadImages,
+		PermManageLeads,
+	},
+	"user": {
+		PermBookCars,
+		PermWatchCars,
+	},
+}
+
+// HasPermission reports whether the role is allowed to perform the action.
+func HasPermission(role string, perm Permission) bool {
+	if role == "admin" {
+		return true
+	}
+	return slices.Contains(rolePermissions[role], perm)
+}
+
+// RequireRole admits only callers whose role is one of roles. Must run after AuthMiddleware.
+func RequireRole(roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "Not authenticated")
+				return
+			}
+
+			if !slices.Contains(roles, principal.Role) {
+				writeError(w, http.StatusForbidden, "Role '"+principal.Role+"' is not allowed; requires one of: "+strings.Join(roles, ", "))
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// RequirePermission admits only callers whose role holds every listed permission. Must run after AuthMiddleware.
+func RequirePermission(perms ...Permission) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "Not authenticated")
+				return
+			}
+
+			for _, perm := range perms {
+				if !HasPermission(principal.Role, perm) {
+					writeError(w, http.StatusForbidden, "Missing permission: "+string(perm))
+					return
+				}
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"Assignment3ADP/internal/domain"
+	"fmt"
+)
+
+// Config selects and configures a notifier.
+type Config struct {
+	Provider     string // "log" (default) or "smtp"
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+// NewNotifier builds the notifier named by cfg.Provider.
+func NewNotifier(cfg Config) (domain.Notifier, error) {
+	switch cfg.Provider {
+	case "", "log":
+		return NewLogNotifier(), nil
+	case "smtp":
+		if cfg.SMTPHost == "" || cfg.From == "" {
+			return nil, fmt.Errorf("smtp notifier requires a host and a from address")
+		}
+		if cfg.SMTPPort == 0 {
+			cfg.SMTPPort = 25
+		}
+		return NewSMTPNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	}
+	return nil, fmt.Errorf("unknown notifier %q", cfg.Provider)
+}
@@ -0,0 +1,19 @@
+package notify
+
+import (
+	"Assignment3ADP/internal/domain"
+	"context"
+	"log"
+)
+
+// LogNotifier writes notifications to the application log instead of delivering them.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, msg domain.Notification) error {
+	log.Printf("[Notify] to=%s <%s> subject=%q body=%q", msg.Username, msg.Email, msg.Subject, msg.Body)
+	return nil
+}
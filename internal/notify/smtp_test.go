@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"Assignment3ADP/internal/domain"
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpStub is a minimal SMTP server that accepts a single message and records it.
+type smtpStub struct {
+	ln       net.Listener
+	from     string
+	rcpt     []string
+	data     string
+	received chan struct{}
+}
+
+func startSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStub{ln: ln, received: make(chan struct{})}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpStub) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStub) serve() {
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 stub ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		switch verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 stub")
+		case "MAIL":
+			s.from = strings.TrimPrefix(cmd, "MAIL FROM:")
+			reply("250 OK")
+		case "RCPT":
+			s.rcpt = append(s.rcpt, strings.TrimPrefix(cmd, "RCPT TO:"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			s.data = b.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			close(s.received)
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPNotifierNotify(t *testing.T) {
+	stub := startSMTPStub(t)
+	n := NewSMTPNotifier("127.0.0.1", stub.port(), "", "", "alerts@autohub.kz")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := n.Notify(ctx, domain.Notification{
+		Username: "aida",
+		Email:    "aida@example.com",
+		Subject:  "Price drop on Toyota Camry",
+		Body:     "Hello aida,\n\nThe price fell.",
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	select {
+	case <-stub.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stub did not receive QUIT")
+	}
+	if stub.from != "<alerts@autohub.kz>" {
+		t.Errorf("MAIL FROM = %q", stub.from)
+	}
+	if len(stub.rcpt) != 1 || stub.rcpt[0] != "<aida@example.com>" {
+		t.Errorf("RCPT TO = %q", stub.rcpt)
+	}
+	for _, want := range []string{
+		"From: alerts@autohub.kz\r\n",
+		"To: aida@example.com\r\n",
+		"Subject: Price drop on Toyota Camry\r\n",
+		"\r\n\r\nHello aida,\r\n\r\nThe price fell.\r\n",
+	} {
+		if !strings.Contains(stub.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, stub.data)
+		}
+	}
+}
+
+func TestSMTPNotifierNoAddress(t *testing.T) {
+	n := NewSMTPNotifier("127.0.0.1", 1, "", "", "alerts@autohub.kz")
+	err := n.Notify(context.Background(), domain.Notification{Username: "aida"})
+	if !errors.Is(err, ErrNoAddress) {
+		t.Fatalf("Notify without email = %v, want ErrNoAddress", err)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	n := &SMTPNotifier{From: "alerts@autohub.kz"}
+	msg := string(n.compose(domain.Notification{
+		Email:   "aida@example.com",
+		Subject: "Цена снижена",
+		Body:    "line one\nline two\r\nline three",
+	}))
+
+	if !strings.Contains(msg, "Subject: =?utf-8?q?") {
+		t.Errorf("non-ASCII subject is not Q-encoded:\n%s", msg)
+	}
+	if !strings.Contains(msg, "Content-Type: text/plain; charset=UTF-8\r\n") {
+		t.Errorf("missing content type:\n%s", msg)
+	}
+	if !strings.HasSuffix(msg, "\r\n\r\nline one\r\nline two\r\nline three\r\n") {
+		t.Errorf("body not normalized to CRLF:\n%q", msg)
+	}
+	if strings.Contains(strings.ReplaceAll(msg, "\r\n", ""), "\n") {
+		t.Errorf("bare LF in message:\n%q", msg)
+	}
+}
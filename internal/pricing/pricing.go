@@ -0,0 +1,103 @@
+// Package pricing turns a car's purchase cost into its retail KZT price
+// according to the pricing rules configured by admins.
+package pricing
+
+import (
+	"Assignment3ADP/internal/domain"
+	"math"
+	"strings"
+)
+
+// DefaultRule applies when no configured rule matches a car. It reproduces the
+// original formula: purchase cost at the official rate, rounded to 100,000 KZT.
+var DefaultRule = domain.PricingRule{RoundingStepKZT: 100000}
+
+// Breakdown explains how a car's KZT price was derived.
+type Breakdown struct {
+	RuleID           string  `json:"rule_id,omitempty"`
+	PurchasePrice    float64 `json:"purchase_price"`
+	PurchaseCurrency string  `json:"purchase_currency"`
+	ExchangeRate     float64 `json:"exchange_rate"`
+	CostKZT          float64 `json:"cost_kzt"`
+	MarkupKZT        float64 `json:"markup_kzt"`
+	CustomsFeeKZT    float64 `json:"customs_fee_kzt"`
+	LogisticsFeeKZT  float64 `json:"logistics_fee_kzt"`
+	NetKZT           float64 `json:"net_kzt"`
+	VATKZT           float64 `json:"vat_kzt"`
+	GrossKZT         float64 `json:"gross_kzt"`
+	RoundingStepKZT  float64 `json:"rounding_step_kzt"`
+	PriceKZT         float64 `json:"price_kzt"`
+	MarginKZT        float64 `json:"margin_kzt"`
+}
+
+// Engine evaluates a fixed set of rules. Build a new one when the rules change.
+type Engine struct {
+	rules []domain.PricingRule
+}
+
+func NewEngine(rules []domain.PricingRule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// Match returns the most specific rule for car: make and category, then make
+// alone, then category alone, then the catch-all. It falls back to DefaultRule.
+func (e *Engine) Match(car *domain.Car) domain.PricingRule {
+	best, bestScore := DefaultRule, -1
+	for _, rule := range e.rules {
+		score := 0
+		if rule.Make != "" {
+			if !strings.EqualFold(rule.Make, car.Make) {
+				continue
+			}
+			score += 2
+		}
+		if rule.Category != "" {
+			if !strings.EqualFold(rule.Category, car.Category) {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+	return best
+}
+
+// Price computes the retail price of car when one unit of its purchase currency
+// costs rate KZT. The markup is never below the rule's minimum margin, and the
+// final rounding goes up whenever rounding to the nearest step would eat into it.
+func (e *Engine) Price(car *domain.Car, rate float64) Breakdown {
+	rule := e.Match(car)
+
+	b := Breakdown{
+		RuleID:           rule.ID,
+		PurchasePrice:    car.PurchasePrice,
+		PurchaseCurrency: car.PurchaseCurrency,
+		ExchangeRate:     rate,
+		CustomsFeeKZT:    rule.CustomsFeeKZT,
+		LogisticsFeeKZT:  rule.LogisticsFeeKZT,
+		RoundingStepKZT:  rule.RoundingStepKZT,
+	}
+	if b.RoundingStepKZT <= 0 {
+		b.RoundingStepKZT = DefaultRule.RoundingStepKZT
+	}
+
+	b.CostKZT = car.PurchasePrice * rate
+	b.MarkupKZT = math.Max(b.CostKZT*rule.MarkupPercent/100, rule.MinMarginKZT)
+	b.NetKZT = b.CostKZT + b.MarkupKZT + b.CustomsFeeKZT + b.LogisticsFeeKZT
+	b.VATKZT = b.NetKZT * rule.VATPercent / 100
+	b.GrossKZT = b.NetKZT + b.VATKZT
+
+	vatFactor := 1 + rule.VATPercent/100
+	margin := func(price float64) float64 {
+		return price/vatFactor - b.CostKZT - b.CustomsFeeKZT - b.LogisticsFeeKZT
+	}
+
+	b.PriceKZT = math.Round(b.GrossKZT/b.RoundingStepKZT) * b.RoundingStepKZT
+	if margin(b.PriceKZT) < rule.MinMarginKZT {
+		b.PriceKZT = math.Ceil(b.GrossKZT/b.RoundingStepKZT) * b.RoundingStepKZT
+	}
+	b.MarginKZT = math.Round(margin(b.PriceKZT)*100) / 100
+	return b
+}
@@ -0,0 +1,141 @@
+package pricing
+
+import (
+	"Assignment3ADP/internal/domain"
+	"math"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	e := NewEngine([]domain.PricingRule{
+		{ID: "all"},
+		{ID: "suv", Category: "suv"},
+		{ID: "toyota", Make: "Toyota"},
+		{ID: "toyota-suv", Make: "Toyota", Category: "suv"},
+	})
+	cases := []struct {
+		make, category, want string
+	}{
+		{"Toyota", "suv", "toyota-suv"},
+		{"TOYOTA", "SUV", "toyota-suv"},
+		{"Toyota", "sedan", "toyota"},
+		{"Lexus", "suv", "suv"},
+		{"BMW", "sedan", "all"},
+	}
+	for _, tc := range cases {
+		car := &domain.Car{Make: tc.make, Category: tc.category}
+		if got := e.Match(car).ID; got != tc.want {
+			t.Errorf("Match(%s %s) = %q, want %q", tc.make, tc.category, got, tc.want)
+		}
+	}
+
+	if got := NewEngine(nil).Match(&domain.Car{Make: "BMW"}); got != DefaultRule {
+		t.Errorf("Match without rules = %+v, want DefaultRule", got)
+	}
+	if got := NewEngine([]domain.PricingRule{{ID: "toyota", Make: "Toyota"}}).Match(&domain.Car{Make: "BMW"}); got != DefaultRule {
+		t.Errorf("Match without a matching rule = %+v, want DefaultRule", got)
+	}
+}
+
+func TestPriceDefaultRule(t *testing.T) {
+	cases := []struct {
+		purchase, want float64
+	}{
+		{20000, 10000000},
+		{20123, 10100000}, // 10,061,500 rounds to the nearest step
+		{20080, 10100000}, // 10,040,000 would round down below cost, so it rounds up
+	}
+	for _, tc := range cases {
+		car := &domain.Car{PurchasePrice: tc.purchase, PurchaseCurrency: "USD"}
+		b := NewEngine(nil).Price(car, 500)
+		if b.PriceKZT != tc.want {
+			t.Errorf("Price(%v USD) = %v, want %v", tc.purchase, b.PriceKZT, tc.want)
+		}
+		if b.RoundingStepKZT != DefaultRule.RoundingStepKZT || b.RuleID != "" {
+			t.Errorf("Price(%v USD) used %+v, want DefaultRule", tc.purchase, b)
+		}
+	}
+}
+
+func TestPriceBreakdown(t *testing.T) {
+	e := NewEngine([]domain.PricingRule{{
+		ID:              "import",
+		MarkupPercent:   10,
+		CustomsFeeKZT:   500000,
+		LogisticsFeeKZT: 300000,
+		VATPercent:      12,
+		RoundingStepKZT: 100000,
+		MinMarginKZT:    1500000,
+	}})
+	b := e.Price(&domain.Car{PurchasePrice: 20000, PurchaseCurrency: "USD"}, 500)
+
+	want := Breakdown{
+		RuleID:           "import",
+		PurchasePrice:    20000,
+		PurchaseCurrency: "USD",
+		ExchangeRate:     500,
+		CostKZT:          10000000,
+		MarkupKZT:        1500000, // 10% is 1,000,000, below the minimum margin
+		CustomsFeeKZT:    500000,
+		LogisticsFeeKZT:  300000,
+		NetKZT:           12300000,
+		VATKZT:           1476000,
+		GrossKZT:         13776000,
+		RoundingStepKZT:  100000,
+		PriceKZT:         13800000,
+		MarginKZT:        1521428.57,
+	}
+	assertBreakdown(t, b, want)
+}
+
+func TestPriceRoundsUpToKeepMinMargin(t *testing.T) {
+	e := NewEngine([]domain.PricingRule{{
+		ID:              "vat",
+		MarkupPercent:   20,
+		VATPercent:      12,
+		RoundingStepKZT: 100000,
+		MinMarginKZT:    2000000,
+	}})
+	b := e.Price(&domain.Car{PurchasePrice: 10000000, PurchaseCurrency: "KZT"}, 1)
+
+	// The gross 13,440,000 rounds to 13,400,000, which leaves a margin of only
+	// 1,964,285.71, so the price goes up to the next step instead.
+	if b.GrossKZT != 13440000 {
+		t.Errorf("GrossKZT = %v, want 13440000", b.GrossKZT)
+	}
+	if b.PriceKZT != 13500000 {
+		t.Errorf("PriceKZT = %v, want 13500000", b.PriceKZT)
+	}
+	if b.MarginKZT < 2000000 {
+		t.Errorf("MarginKZT = %v, below the minimum margin", b.MarginKZT)
+	}
+}
+
+func assertBreakdown(t *testing.T, got, want Breakdown) {
+	t.Helper()
+	if got.RuleID != want.RuleID || got.PurchaseCurrency != want.PurchaseCurrency {
+		t.Errorf("Price = %+v, want %+v", got, want)
+	}
+	amounts := []struct {
+		name      string
+		got, want float64
+	}{
+		{"PurchasePrice", got.PurchasePrice, want.PurchasePrice},
+		{"ExchangeRate", got.ExchangeRate, want.ExchangeRate},
+		{"CostKZT", got.CostKZT, want.CostKZT},
+		{"MarkupKZT", got.MarkupKZT, want.MarkupKZT},
+		{"CustomsFeeKZT", got.CustomsFeeKZT, want.CustomsFeeKZT},
+		{"LogisticsFeeKZT", got.LogisticsFeeKZT, want.LogisticsFeeKZT},
+		{"NetKZT", got.NetKZT, want.NetKZT},
+		{"VATKZT", got.VATKZT, want.VATKZT},
+		{"GrossKZT", got.GrossKZT, want.GrossKZT},
+		{"RoundingStepKZT", got.RoundingStepKZT, want.RoundingStepKZT},
+		{"PriceKZT", got.PriceKZT, want.PriceKZT},
+		{"MarginKZT", got.MarginKZT, want.MarginKZT},
+	}
+	for _, a := range amounts {
+		if math.Abs(a.got-a.want) > 0.005 {
+			t.Errorf("%s = %v, want %v", a.name, a.got, a.want)
+		}
+	}
+}
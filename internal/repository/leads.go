@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"Assignment3ADP/internal/domain"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const leadColumns = `l.id, l.car_id, COALESCE(l.car_model, ''), COALESCE(l.customer_name, ''), l.customer_phone, l.inquiry_type,
+	l.details, l.status, l.assigned_user_id, u.username, l.last_inquiry_at, l.created_at, l.updated_at`
+
+const leadsFrom = " FROM leads l LEFT JOIN users u ON u.id = l.assigned_user_id"
+
+func scanLead(row rowScanner) (*domain.Lead, error) {
+	var l domain.Lead
+	var carID, assignedID, assignedName sql.NullString
+	var details []byte
+	if err := row.Scan(&l.ID, &carID, &l.CarModel, &l.CustomerName, &l.CustomerPhone, &l.InquiryType,
+		&details, &l.Status, &assignedID, &assignedName, &l.LastInquiryAt, &l.CreatedAt, &l.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(details, &l.Details); err != nil {
+		return nil, err
+	}
+	l.CarID, l.AssignedUserID, l.AssignedUsername = carID.String, assignedID.String, assignedName.String
+	return &l, nil
+}
+
+// IntakeLead records a customer inquiry. If the same phone already has an open
+// lead of the same inquiry type about the same car with an inquiry inside
+// dedupWindow, the inquiry is merged into it instead of opening a new lead: the
+// lead takes the latest details and, when given, the latest customer name.
+// Interest in another car or another type of inquiry gets its own lead. Either
+// way the inquiry is kept as a touch, and lead is replaced with the stored lead.
+func (r *PostgresRepo) IntakeLead(lead *domain.Lead, dedupWindow time.Duration) (bool, error) {
+	details, err := json.Marshal(lead.Details)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Serialize intake per phone so two quick submissions cannot both open a lead.
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('lead:' || $1))", lead.CustomerPhone); err != nil {
+		return false, err
+	}
+
+	var leadID string
+	err = tx.QueryRow(`SELECT id FROM leads
+			  WHERE customer_phone = $1 AND status NOT IN ($2, $3)
+			    AND last_inquiry_at >= CURRENT_TIMESTAMP - $4 * INTERVAL '1 second'
+			    AND car_id IS NOT DISTINCT FROM $5::uuid AND inquiry_type = $6
+			  ORDER BY last_inquiry_at DESC LIMIT 1`,
+		lead.CustomerPhone, domain.LeadWon, domain.LeadLost, dedupWindow.Seconds(), nullIfEmpty(lead.CarID),
+		lead.InquiryType).Scan(&leadID)
+	merged := err == nil
+	switch {
+	case merged:
+		_, err = tx.Exec(`UPDATE leads SET details = $2, customer_name = COALESCE(NULLIF($3, ''), customer_name),
+				last_inquiry_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1`, leadID, string(details), lead.CustomerName)
+	case err == sql.ErrNoRows:
+		err = tx.QueryRow(`INSERT INTO leads (car_id, car_model, customer_name, customer_phone, inquiry_type, details, status)
+				  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, nullIfEmpty(lead.CarID), lead.CarModel,
+			lead.CustomerName, lead.CustomerPhone, lead.InquiryType, string(details), lead.Status).Scan(&leadID)
+	}
+	if isForeignKeyViolation(err) {
+		return false, domain.ErrCarNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`INSERT INTO lead_touches (lead_id, car_id, car_model, customer_name, inquiry_type, details)
+			  VALUES ($1, $2, $3, $4, $5, $6)`, leadID, nullIfEmpty(lead.CarID), lead.CarModel, lead.CustomerName,
+		lead.InquiryType, string(details))
+	if isForeignKeyViolation(err) {
+		return false, domain.ErrCarNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	stored, err := scanLead(tx.QueryRow("SELECT "+leadColumns+leadsFrom+" WHERE l.id = $1", leadID))
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	*lead = *stored
+	return merged, nil
+}
+
+// GetLeadTouches lists the inquiries merged into a lead, oldest first.
+func (r *PostgresRepo) GetLeadTouches(leadID string) ([]domain.LeadTouch, error) {
+	query := `SELECT id, lead_id, car_id, COALESCE(car_model, ''), COALESCE(customer_name, ''), inquiry_type, details, created_at
+			  FROM lead_touches WHERE lead_id = $1 ORDER BY created_at, id`
+	rows, err := r.DB.Query(query, leadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	touches := []domain.LeadTouch{}
+	for rows.Next() {
+		var t domain.LeadTouch
+		var carID sql.NullString
+		var details []byte
+		if err := rows.Scan(&t.ID, &t.LeadID, &carID, &t.CarModel, &t.CustomerName, &t.InquiryType, &details, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &t.Details); err != nil {
+			return nil, err
+		}
+		t.CarID = carID.String
+		touches = append(touches, t)
+	}
+	return touches, rows.Err()
+}
+
+// GetAllLeads returns every lead, newest first.
+func (r *PostgresRepo) GetAllLeads() ([]domain.Lead, error) {
+	return r.fetchLeads("SELECT " + leadColumns + leadsFrom + " ORDER BY l.created_at DESC")
+}
+
+// GetLeadsByCar returns the leads that asked about a car, newest first.
+func (r *PostgresRepo) GetLeadsByCar(carID string) ([]domain.Lead, error) {
+	return r.fetchLeads("SELECT "+leadColumns+leadsFrom+" WHERE l.car_id = $1 ORDER BY l.created_at DESC", carID)
+}
+
+// fetchLeads runs a query selecting leadColumns and scans every row.
+func (r *PostgresRepo) fetchLeads(query string, args ...interface{}) ([]domain.Lead, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leads := []domain.Lead{}
+	for rows.Next() {
+		l, err := scanLead(rows)
+		if err != nil {
+			return nil, err
+		}
+		leads = append(leads, *l)
+	}
+	return leads, rows.Err()
+}
+
+// GetLeadByID fetches a single lead without its notes.
+func (r *PostgresRepo) GetLeadByID(id string) (*domain.Lead, error) {
+	l, err := scanLead(r.DB.QueryRow("SELECT "+leadColumns+leadsFrom+" WHERE l.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrLeadNotFound
+	}
+	return l, err
+}
+
+// UpdateLead applies patch if the lead is still at expectedStatus, so two
+// salespeople moving the same lead cannot skip pipeline validation.
+func (r *PostgresRepo) UpdateLead(id string, patch domain.LeadPatch, expectedStatus string) (*domain.Lead, error) {
+	assign := patch.AssignedUserID != nil
+	var assignedID interface{}
+	if assign {
+		assignedID = nullIfEmpty(*patch.AssignedUserID)
+	}
+
+	query := `UPDATE leads SET
+				status = COALESCE($3, status),
+				assigned_user_id = CASE WHEN $4::boolean THEN $5::uuid ELSE assigned_user_id END,
+				updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND status = $2`
+	res, err := r.DB.Exec(query, id, expectedStatus, patch.Status, assign, assignedID)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		if _, getErr := r.GetLeadByID(id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, domain.ErrLeadChanged
+	}
+	return r.GetLeadByID(id)
+}
+
+// AddLeadNote stores a note and fills in its ID and timestamp.
+func (r *PostgresRepo) AddLeadNote(note *domain.LeadNote) error {
+	query := `INSERT INTO lead_notes (lead_id, author_id, body) VALUES ($1, $2, $3) RETURNING id, created_at`
+	err := r.DB.QueryRow(query, note.LeadID, nullIfEmpty(note.AuthorID), note.Body).Scan(&note.ID, &note.CreatedAt)
+	if isForeignKeyViolation(err) {
+		return domain.ErrLeadNotFound
+	}
+	return err
+}
+
+// GetLeadNotes lists a lead's notes, oldest first.
+func (r *PostgresRepo) GetLeadNotes(leadID string) ([]domain.LeadNote, error) {
+	query := `SELECT n.id, n.lead_id, n.author_id, u.username, n.body, n.created_at
+			  FROM lead_notes n LEFT JOIN users u ON u.id = n.author_id
+			  WHERE n.lead_id = $1 ORDER BY n.created_at, n.id`
+	rows, err := r.DB.Query(query, leadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []domain.LeadNote{}
+	for rows.Next() {
+		var n domain.LeadNote
+		var authorID, authorName sql.NullString
+		if err := rows.Scan(&n.ID, &n.LeadID, &authorID, &authorName, &n.Body, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.AuthorID, n.AuthorUsername = authorID.String, authorName.String
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
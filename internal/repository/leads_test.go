@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"Assignment3ADP/internal/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testDriveLead(carID, name, date, slot string) *domain.Lead {
+	return &domain.Lead{
+		CarID:         carID,
+		CarModel:      "Toyota Camry",
+		CustomerName:  name,
+		CustomerPhone: "+77011234567",
+		InquiryType:   domain.InquiryTestDrive,
+		Details:       domain.InquiryDetails{PreferredDate: date, TimeSlot: slot},
+		Status:        domain.LeadNew,
+	}
+}
+
+func TestIntakeLeadMergeTakesLatestDetails(t *testing.T) {
+	repo := testRepo(t)
+	car := createTestCar(t, repo, "JTDBR32E720012345")
+
+	first := testDriveLead(car.ID, "Aida", "2026-11-02", "morning")
+	if merged, err := repo.IntakeLead(first, time.Hour); err != nil || merged {
+		t.Fatalf("first IntakeLead = %v, %v; want a new lead", merged, err)
+	}
+
+	second := testDriveLead(car.ID, "Aida Serikova", "2026-11-05", "evening")
+	merged, err := repo.IntakeLead(second, time.Hour)
+	if err != nil || !merged {
+		t.Fatalf("second IntakeLead = %v, %v; want it merged", merged, err)
+	}
+	if second.ID != first.ID {
+		t.Fatalf("merged into lead %s, want %s", second.ID, first.ID)
+	}
+
+	// A resubmission without a name keeps the one already on the lead.
+	third := testDriveLead(car.ID, "", "2026-11-06", "afternoon")
+	if merged, err := repo.IntakeLead(third, time.Hour); err != nil || !merged {
+		t.Fatalf("third IntakeLead = %v, %v; want it merged", merged, err)
+	}
+
+	leads, err := repo.GetAllLeads()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leads) != 1 {
+		t.Fatalf("%d leads, want 1", len(leads))
+	}
+	got := leads[0]
+	if got.CustomerName != "Aida Serikova" {
+		t.Errorf("customer_name = %q, want the latest name given", got.CustomerName)
+	}
+	if got.Details.PreferredDate != "2026-11-06" || got.Details.TimeSlot != "afternoon" {
+		t.Errorf("details = %+v, want the latest submission", got.Details)
+	}
+
+	touches, err := repo.GetLeadTouches(got.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(touches) != 3 || touches[0].Details.PreferredDate != "2026-11-02" {
+		t.Errorf("touches = %+v, want all three submissions, oldest first", touches)
+	}
+}
+
+func TestIntakeLeadDedupWindow(t *testing.T) {
+	repo := testRepo(t)
+	car := createTestCar(t, repo, "JTDBR32E720012345")
+	other := createTestCar(t, repo, "KNAGM4A70F5000001")
+
+	first := testDriveLead(car.ID, "Aida", "2026-11-02", "morning")
+	if _, err := repo.IntakeLead(first, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		lead   *domain.Lead
+		setup  string // SQL run against the first lead before the inquiry
+		merged bool
+	}{
+		{name: "within the window", lead: testDriveLead(car.ID, "Aida", "2026-11-03", "evening"), merged: true},
+		{name: "another car", lead: testDriveLead(other.ID, "Aida", "2026-11-03", "evening")},
+		{
+			name:  "after the window",
+			lead:  testDriveLead(car.ID, "Aida", "2026-11-04", "evening"),
+			setup: "UPDATE leads SET last_inquiry_at = CURRENT_TIMESTAMP - INTERVAL '2 hours' WHERE id = $1",
+		},
+	}
+	for _, tc := range cases {
+		if tc.setup != "" {
+			if _, err := repo.DB.Exec(tc.setup, first.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		merged, err := repo.IntakeLead(tc.lead, time.Hour)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if merged != tc.merged || (merged && tc.lead.ID != first.ID) {
+			t.Errorf("%s: merged = %v into %s, want %v", tc.name, merged, tc.lead.ID, tc.merged)
+		}
+	}
+}
+
+func TestIntakeLeadConcurrentSubmissions(t *testing.T) {
+	repo := testRepo(t)
+	car := createTestCar(t, repo, "JTDBR32E720012345")
+
+	// The advisory lock serializes intake per phone, so simultaneous
+	// submissions still end up in a single lead.
+	const submissions = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, submissions)
+	for i := 0; i < submissions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.IntakeLead(testDriveLead(car.ID, "Aida", "2026-11-02", "morning"), time.Hour)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	leads, err := repo.GetAllLeads()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leads) != 1 {
+		t.Fatalf("%d leads, want 1", len(leads))
+	}
+	touches, err := repo.GetLeadTouches(leads[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(touches) != submissions {
+		t.Errorf("%d touches, want %d", len(touches), submissions)
+	}
+}
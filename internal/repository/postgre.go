@@ -3,8 +3,11 @@ package repository
 import (
 	"Assignment3ADP/internal/domain"
 	"database/sql"
-	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type PostgresRepo struct {
@@ -15,30 +18,55 @@ func NewPostgresRepo(db *sql.DB) *PostgresRepo {
 	return &PostgresRepo{DB: db}
 }
 
-// GetCarByID fetches a single car's details including the image.
-func (r *PostgresRepo) GetCarByID(id string) (*domain.Car, error) {
-	var c domain.Car
-	query := "SELECT id, vin, make, model, price_usd, price_kzt, status, image_url FROM cars WHERE id = $1"
+// carColumns is the column list scanned by scanCar.
+// USD purchases leave purchase_price NULL, so price_usd stays the single source for them.
+const carColumns = "id, vin, make, model, category, year, mileage, color, location, description, purchase_currency, COALESCE(purchase_price, price_usd), price_usd, price_kzt, price_locked, price_locked_until, status, status_changed_by, status_changed_at, sold_at, image_url, version, created_at, updated_at"
 
-	var imgUrl sql.NullString
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	err := r.DB.QueryRow(query, id).
-		Scan(&c.ID, &c.VIN, &c.Make, &c.Model, &c.PriceUSD, &c.PriceKZT, &c.Status, &imgUrl)
+// scanCar reads a row selected with carColumns.
+func scanCar(row rowScanner) (*domain.Car, error) {
+	var c domain.Car
+	var imgUrl, changedBy, category, color, location, description sql.NullString // Handle potential NULLs safely
+	var year, mileage sql.NullInt64
+	var changedAt, soldAt, lockedUntil sql.NullTime
 
-	if err == sql.ErrNoRows {
-		return nil, domain.ErrCarNotFound
+	if err := row.Scan(&c.ID, &c.VIN, &c.Make, &c.Model, &category, &year, &mileage, &color, &location, &description,
+		&c.PurchaseCurrency, &c.PurchasePrice, &c.PriceUSD, &c.PriceKZT, &c.PriceLocked, &lockedUntil, &c.Status, &changedBy, &changedAt, &soldAt,
+		&imgUrl, &c.Version, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
 	}
-
+	c.Year, c.Mileage = int(year.Int64), int(mileage.Int64)
+	c.Category, c.Color, c.Location = category.String, color.String, location.String
+	c.Description = description.String
 	if imgUrl.Valid {
 		c.ImageURL = imgUrl.String
 	}
-	return &c, err
+	if changedBy.Valid {
+		c.StatusChangedBy = changedBy.String
+	}
+	if changedAt.Valid {
+		c.StatusChangedAt = &changedAt.Time
+	}
+	if soldAt.Valid {
+		c.SoldAt = &soldAt.Time
+	}
+	if lockedUntil.Valid {
+		c.PriceLockedUntil = &lockedUntil.Time
+	}
+	return &c, nil
 }
 
-// UpdatePrice updates the calculated KZT price.
-func (r *PostgresRepo) UpdatePrice(id string, priceKZT float64) error {
-	_, err := r.DB.Exec("UPDATE cars SET price_kzt = $1 WHERE id = $2", priceKZT, id)
-	return err
+// GetCarByID fetches a single car's details including the image.
+func (r *PostgresRepo) GetCarByID(id string) (*domain.Car, error) {
+	c, err := scanCar(r.DB.QueryRow("SELECT "+carColumns+" FROM cars WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrCarNotFound
+	}
+	return c, err
 }
 
 // BookCar performs a transaction to reserve a car.
@@ -50,13 +78,20 @@ func (r *PostgresRepo) BookCar(carID string, userID string) error {
 	defer tx.Rollback()
 
 	var status string
-	if err := tx.QueryRow("SELECT status FROM cars WHERE id = $1 FOR UPDATE", carID).Scan(&status); err != nil {
+	err = tx.QueryRow("SELECT status FROM cars WHERE id = $1 FOR UPDATE", carID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return domain.ErrCarNotFound
+	}
+	if err != nil {
 		return err
 	}
-	if status != "available" && status != "transit" {
+	if domain.ValidateStatusTransition(status, domain.StatusReserved) != nil {
 		return domain.ErrCarNotAvailable
 	}
-	if _, err := tx.Exec("UPDATE cars SET status = 'reserved', user_id = $2 WHERE id = $1", userID, carID); err != nil {
+	if _, err := tx.Exec("UPDATE cars SET user_id = $2 WHERE id = $1", carID, userID); err != nil {
+		return err
+	}
+	if err := setStatus(tx, carID, status, domain.StatusReserved, userID, "Booked by customer"); err != nil {
 		return err
 	}
 	return tx.Commit()
@@ -65,89 +100,211 @@ func (r *PostgresRepo) BookCar(carID string, userID string) error {
 // GetUserByUsername returns pointer to domain.User
 func (r *PostgresRepo) GetUserByUsername(username string) (*domain.User, error) {
 	u := &domain.User{}
-	query := "SELECT id, username, password_hash, role FROM users WHERE username = $1"
-	err := r.DB.QueryRow(query, username).Scan(&u.ID, &u.Username, &u.Password, &u.Role)
+	query := "SELECT id, username, COALESCE(email, ''), password_hash, role, disabled, created_at FROM users WHERE username = $1"
+	err := r.DB.QueryRow(query, username).Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.Role, &u.Disabled, &u.CreatedAt)
 	if err == sql.ErrNoRows {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 	return u, err
 }
 
-// CreateUser creates new user
-func (r *PostgresRepo) CreateUser(u *domain.User) error {
-	_, err := r.DB.Exec("INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3)",
-		u.Username, u.Password, u.Role)
-	return err
+// GetUserByID returns pointer to domain.User
+func (r *PostgresRepo) GetUserByID(id string) (*domain.User, error) {
+	u := &domain.User{}
+	query := "SELECT id, username, COALESCE(email, ''), password_hash, role, disabled, created_at FROM users WHERE id = $1"
+	err := r.DB.QueryRow(query, id).Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.Role, &u.Disabled, &u.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrUserNotFound
+	}
+	return u, err
 }
 
-// CreateLead creates new lead
-func (r *PostgresRepo) CreateLead(lead *domain.Lead) error {
-	query := `INSERT INTO leads (car_model, customer_name, customer_phone, inquiry_type, status) 
-			  VALUES ($1, $2, $3, $4, 'new')`
-	_, err := r.DB.Exec(query, lead.CarModel, lead.CustomerName, lead.CustomerPhone, lead.InquiryType)
+// CreateUser creates new user
+func (r *PostgresRepo) CreateUser(u *domain.User) error {
+	err := r.DB.QueryRow("INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3) RETURNING id, created_at",
+		u.Username, u.Password, u.Role).Scan(&u.ID, &u.CreatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrUsernameTaken
+	}
 	return err
 }
 
-// GetAllLeads
-func (r *PostgresRepo) GetAllLeads() ([]domain.Lead, error) {
-	rows, err := r.DB.Query("SELECT id, car_model, customer_name, customer_phone, inquiry_type, status, created_at FROM leads ORDER BY created_at DESC")
-	if err != nil {
-		return nil, err
+// CreateCar adds a new vehicle to the inventory and fills in its generated ID.
+func (r *PostgresRepo) CreateCar(c *domain.Car) error {
+	var purchasePrice interface{}
+	if c.PurchaseCurrency != "USD" {
+		purchasePrice = c.PurchasePrice
 	}
-	defer rows.Close()
 
-	var leads []domain.Lead
-	for rows.Next() {
-		var l domain.Lead
-		rows.Scan(&l.ID, &l.CarModel, &l.CustomerName, &l.CustomerPhone, &l.InquiryType, &l.Status, &l.CreatedAt)
-		leads = append(leads, l)
+	query := `INSERT INTO cars (vin, make, model, year, mileage, color, location, purchase_currency, purchase_price,
+			  price_usd, status, image_url, created_at, category, description)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, version`
+	err := r.DB.QueryRow(query, c.VIN, c.Make, c.Model, nullIfZero(c.Year), c.Mileage, nullIfEmpty(c.Color),
+		nullIfEmpty(c.Location), c.PurchaseCurrency, purchasePrice, c.PriceUSD, c.Status, c.ImageURL, time.Now(),
+		nullIfEmpty(c.Category), nullIfEmpty(c.Description)).
+		Scan(&c.ID, &c.Version)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateVIN
 	}
-	return leads, nil
+	return err
 }
 
-// CreateCar adds a new vehicle to the inventory (Updated with image_url).
-func (r *PostgresRepo) CreateCar(c *domain.Car) error {
-	query := `INSERT INTO cars (vin, make, model, price_usd, status, image_url, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err := r.DB.Exec(query, c.VIN, c.Make, c.Model, c.PriceUSD, c.Status, c.ImageURL, time.Now())
-	return err
+// UpdateCar applies a partial update if the car is still at expectedVersion,
+// bumping version and updated_at. A purchase price is only written together
+// with its currency, and is stored as NULL for USD like in CreateCar.
+func (r *PostgresRepo) UpdateCar(id string, patch domain.CarPatch, expectedVersion int) (*domain.Car, error) {
+	query := `UPDATE cars SET
+				vin = COALESCE($3, vin),
+				make = COALESCE($4, make),
+				model = COALESCE($5, model),
+				price_usd = COALESCE($6, price_usd),
+				image_url = COALESCE($7, image_url),
+				year = COALESCE($8, year),
+				mileage = COALESCE($9, mileage),
+				color = COALESCE($10, color),
+				location = COALESCE($11, location),
+				category = COALESCE($12, category),
+				description = COALESCE($13, description),
+				purchase_currency = COALESCE($14, purchase_currency),
+				purchase_price = CASE WHEN $14::varchar IS NULL THEN purchase_price
+					WHEN $14 = 'USD' THEN NULL ELSE $15::decimal END,
+				version = version + 1,
+				updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND version = $2
+			  RETURNING ` + carColumns
+	c, err := scanCar(r.DB.QueryRow(query, id, expectedVersion,
+		patch.VIN, patch.Make, patch.Model, patch.PriceUSD, patch.ImageURL,
+		patch.Year, patch.Mileage, patch.Color, patch.Location, patch.Category, patch.Description,
+		patch.PurchaseCurrency, patch.PurchasePrice))
+	if isUniqueViolation(err) {
+		return nil, domain.ErrDuplicateVIN
+	}
+	if err == sql.ErrNoRows {
+		// Either the car is gone or someone else bumped the version first.
+		if _, getErr := r.GetCarByID(id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, domain.ErrVersionConflict
+	}
+	return c, err
 }
 
 // GetAllCars retrieves the full inventory.
 func (r *PostgresRepo) GetAllCars() ([]domain.Car, error) {
-	return r.fetchCars("SELECT id, vin, make, model, price_usd, price_kzt, status, image_url FROM cars ORDER BY created_at DESC")
+	return r.fetchCars("SELECT " + carColumns + " FROM cars ORDER BY created_at DESC")
 }
 
 // GetAvailableCars retrieves only cars valid for customers to buy.
 func (r *PostgresRepo) GetAvailableCars() ([]domain.Car, error) {
-	return r.fetchCars("SELECT id, vin, make, model, price_usd, price_kzt, status, image_url FROM cars WHERE status IN ('available', 'transit')")
+	return r.fetchCars("SELECT " + carColumns + " FROM cars WHERE status IN ('available', 'transit')")
+}
+
+// carSortColumns maps CarFilter sort fields to SQL expressions.
+var carSortColumns = map[string]string{
+	domain.SortNewest:  "created_at",
+	domain.SortPrice:   "price_kzt",
+	domain.SortYear:    "year",
+	domain.SortMileage: "mileage",
+	domain.SortMake:    "LOWER(make)",
+}
+
+// FindCars returns one page of cars matching f and the number of matches across all pages.
+func (r *PostgresRepo) FindCars(f domain.CarFilter) ([]domain.Car, int, error) {
+	var conds []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.Make != "" {
+		conds = append(conds, "LOWER(make) = LOWER("+arg(f.Make)+")")
+	}
+	if f.Model != "" {
+		conds = append(conds, "model ILIKE "+arg("%"+escapeLike(f.Model)+"%"))
+	}
+	if len(f.Statuses) > 0 {
+		conds = append(conds, "status = ANY("+arg(pq.StringArray(f.Statuses))+")")
+	}
+	if f.MinPriceKZT > 0 {
+		conds = append(conds, "price_kzt >= "+arg(f.MinPriceKZT))
+	}
+	if f.MaxPriceKZT > 0 {
+		conds = append(conds, "price_kzt <= "+arg(f.MaxPriceKZT))
+	}
+	if f.MinYear > 0 {
+		conds = append(conds, "year >= "+arg(f.MinYear))
+	}
+	if f.MaxYear > 0 {
+		conds = append(conds, "year <= "+arg(f.MaxYear))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := r.DB.QueryRow("SELECT COUNT(*) FROM cars"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	sortCol, ok := carSortColumns[f.Sort]
+	if !ok {
+		sortCol = carSortColumns[domain.SortNewest]
+	}
+	dir := "ASC"
+	if f.Desc {
+		dir = "DESC"
+	}
+	// id breaks ties so pages never overlap or skip rows.
+	query := "SELECT " + carColumns + " FROM cars" + where +
+		" ORDER BY " + sortCol + " " + dir + " NULLS LAST, id " + dir
+	if f.PageSize > 0 {
+		query += " LIMIT " + arg(f.PageSize) + " OFFSET " + arg(f.Offset())
+	}
+
+	cars, err := r.fetchCars(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if cars == nil {
+		cars = []domain.Car{}
+	}
+	return cars, total, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 // GetCarsInTransit finds cars that require currency updates.
 func (r *PostgresRepo) GetCarsInTransit() ([]domain.Car, error) {
-	return r.fetchCars("SELECT id, vin, make, model, price_usd, price_kzt, status, image_url FROM cars WHERE status = 'transit'")
+	return r.fetchCars("SELECT " + carColumns + " FROM cars WHERE status = 'transit'")
+}
+
+// GetCarsByUser lists the cars reserved or bought by a customer.
+func (r *PostgresRepo) GetCarsByUser(userID string) ([]domain.Car, error) {
+	return r.fetchCars("SELECT "+carColumns+" FROM cars WHERE user_id = $1 ORDER BY updated_at DESC", userID)
 }
 
-// fetchCars helper updated to scan image_url.
-func (r *PostgresRepo) fetchCars(query string) ([]domain.Car, error) {
-	rows, err := r.DB.Query(query)
+// fetchCars runs a query selecting carColumns and scans every row.
+func (r *PostgresRepo) fetchCars(query string, args ...interface{}) ([]domain.Car, error) {
+	rows, err := r.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var cars []domain.Car
 	for rows.Next() {
-		var c domain.Car
-		var imgUrl sql.NullString // Handle potential NULLs safely
-
-		if err := rows.Scan(&c.ID, &c.VIN, &c.Make, &c.Model, &c.PriceUSD, &c.PriceKZT, &c.Status, &imgUrl); err != nil {
+		c, err := scanCar(rows)
+		if err != nil {
 			return nil, err
 		}
-		if imgUrl.Valid {
-			c.ImageURL = imgUrl.String
-		}
-		cars = append(cars, c)
+		cars = append(cars, *c)
 	}
-	return cars, nil
+	return cars, rows.Err()
 }
 
 // DeleteCar removes a vehicle from the system.
@@ -156,8 +313,90 @@ func (r *PostgresRepo) DeleteCar(id string) error {
 	return err
 }
 
-// UpdateStatus changes the status of a vehicle.
-func (r *PostgresRepo) UpdateStatus(id string, status string) error {
-	_, err := r.DB.Exec("UPDATE cars SET status = $1 WHERE id = $2", status, id)
+// UpdateStatus moves a vehicle to a new status, validating the transition against
+// the locked current status and recording who made the change in car_status_events.
+func (r *PostgresRepo) UpdateStatus(change domain.StatusChange) error {
+	id, status := change.CarID, change.To
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current string
+	err = tx.QueryRow("SELECT status FROM cars WHERE id = $1 FOR UPDATE", id).Scan(&current)
+	if err == sql.ErrNoRows {
+		return domain.ErrCarNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := domain.ValidateStatusTransition(current, status); err != nil {
+		return err
+	}
+
+	if err := setStatus(tx, id, current, status, change.ActorID, change.Reason); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setStatus writes a status change and its history event inside tx.
+// sold_at tracks the moment the car was last sold and is cleared on return.
+// A car back on sale no longer belongs to the customer who booked or bought it.
+func setStatus(tx *sql.Tx, carID, from, to, actorID, reason string) error {
+	query := `UPDATE cars SET status = $1, status_changed_by = $3, status_changed_at = CURRENT_TIMESTAMP,
+			  sold_at = CASE WHEN $1 = 'sold' THEN CURRENT_TIMESTAMP ELSE NULL END,
+			  user_id = CASE WHEN $1 = 'available' THEN NULL ELSE user_id END,
+			  version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if _, err := tx.Exec(query, to, carID, nullIfEmpty(actorID)); err != nil {
+		return err
+	}
+
+	event := `INSERT INTO car_status_events (car_id, from_status, to_status, actor_id, reason)
+			  VALUES ($1, $2, $3, $4, $5)`
+	_, err := tx.Exec(event, carID, from, to, nullIfEmpty(actorID), nullIfEmpty(reason))
 	return err
 }
+
+// GetCarStatusHistory returns a car's status events, oldest first.
+func (r *PostgresRepo) GetCarStatusHistory(carID string) ([]domain.CarStatusEvent, error) {
+	query := `SELECT e.id, e.car_id, e.from_status, e.to_status, e.actor_id, u.username, e.reason, e.occurred_at
+			  FROM car_status_events e LEFT JOIN users u ON u.id = e.actor_id
+			  WHERE e.car_id = $1 ORDER BY e.occurred_at, e.id`
+	rows, err := r.DB.Query(query, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.CarStatusEvent{}
+	for rows.Next() {
+		var e domain.CarStatusEvent
+		var actorID, actorName, reason sql.NullString
+		if err := rows.Scan(&e.ID, &e.CarID, &e.FromStatus, &e.ToStatus, &actorID, &actorName, &reason, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+		e.ActorID, e.ActorUsername, e.Reason = actorID.String, actorName.String, reason.String
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// nullIfEmpty maps "" to SQL NULL for optional columns.
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nullIfZero maps 0 to SQL NULL for optional numeric columns.
+func nullIfZero(n int) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
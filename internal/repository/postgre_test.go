@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"Assignment3ADP/internal/domain"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// testRepo connects to TEST_DATABASE_URL and recreates the schema there, so it
+// must point at a throwaway database. Without it the test is skipped.
+func testRepo(t *testing.T) *PostgresRepo {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	schema, err := os.ReadFile("../../migrations/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatalf("apply schema: %v", err)
+	}
+	return NewPostgresRepo(db)
+}
+
+// createTestCar stores an available car bought in USD.
+func createTestCar(t *testing.T, repo *PostgresRepo, vin string) *domain.Car {
+	t.Helper()
+	car := &domain.Car{
+		VIN:              vin,
+		Make:             "Toyota",
+		Model:            "Camry",
+		PurchasePrice:    20000,
+		PurchaseCurrency: "USD",
+		PriceUSD:         20000,
+		Status:           domain.StatusAvailable,
+	}
+	if err := repo.CreateCar(car); err != nil {
+		t.Fatalf("CreateCar: %v", err)
+	}
+	return car
+}
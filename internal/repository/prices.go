@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"Assignment3ADP/internal/domain"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// UpdatePrice sets a car's KZT price and appends the change to price_changes in one transaction.
+// A change without Lock is refused with ErrPriceLocked while a manual override is active,
+// and otherwise clears any lapsed lock. The car's version is bumped so pending edits see it.
+func (r *PostgresRepo) UpdatePrice(change *domain.PriceChange) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	err = tx.QueryRow(`SELECT price_kzt, price_locked AND (price_locked_until IS NULL OR price_locked_until > CURRENT_TIMESTAMP)
+					   FROM cars WHERE id = $1 FOR UPDATE`, change.CarID).Scan(&change.OldPriceKZT, &locked)
+	if err == sql.ErrNoRows {
+		return domain.ErrCarNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if locked && !change.Lock {
+		return domain.ErrPriceLocked
+	}
+
+	query := `UPDATE cars SET price_kzt = $1, price_locked = $3, price_locked_until = $4,
+			  version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if _, err := tx.Exec(query, change.NewPriceKZT, change.CarID, change.Lock, change.LockedUntil); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO price_changes (car_id, old_price_kzt, new_price_kzt, exchange_rate_id, reason, changed_by)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, changed_at`
+	if err := tx.QueryRow(query, change.CarID, change.OldPriceKZT, change.NewPriceKZT,
+		nullIfEmpty(change.ExchangeRateID), nullIfEmpty(change.Reason), nullIfEmpty(change.ChangedBy)).
+		Scan(&change.ID, &change.ChangedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdatePrices applies automatic price changes in a single statement, so either every
+// change is recorded in price_changes or none is. Cars under an active manual lock and
+// cars already at the new price are left alone; the changes actually applied are returned.
+// Repriced cars get a new version, so an edit based on the old price fails its version check.
+func (r *PostgresRepo) UpdatePrices(changes []domain.PriceChange) ([]domain.PriceChange, error) {
+	applied := []domain.PriceChange{}
+	if len(changes) == 0 {
+		return applied, nil
+	}
+
+	ids := make(pq.StringArray, len(changes))
+	prices := make(pq.Float64Array, len(changes))
+	rateIDs := make(pq.StringArray, len(changes))
+	reasons := make(pq.StringArray, len(changes))
+	for i, c := range changes {
+		ids[i], prices[i], rateIDs[i], reasons[i] = c.CarID, c.NewPriceKZT, c.ExchangeRateID, c.Reason
+	}
+
+	query := `WITH input AS (
+				SELECT * FROM unnest($1::uuid[], $2::numeric[], $3::text[], $4::text[])
+					AS t(car_id, new_price, rate_id, reason)
+			  ), unlocked AS (
+				SELECT c.id, c.price_kzt AS old_price FROM cars c JOIN input i ON i.car_id = c.id
+				WHERE NOT (c.price_locked AND (c.price_locked_until IS NULL OR c.price_locked_until > CURRENT_TIMESTAMP))
+				FOR UPDATE OF c
+			  ), updated AS (
+				UPDATE cars c SET price_kzt = i.new_price, price_locked = FALSE, price_locked_until = NULL,
+					version = c.version + 1, updated_at = CURRENT_TIMESTAMP
+				FROM input i JOIN unlocked cur ON cur.id = i.car_id
+				WHERE c.id = i.car_id AND c.price_kzt IS DISTINCT FROM i.new_price
+				RETURNING c.id, cur.old_price, i.new_price, i.rate_id, i.reason
+			  )
+			  INSERT INTO price_changes (car_id, old_price_kzt, new_price_kzt, exchange_rate_id, reason)
+			  SELECT id, old_price, new_price, NULLIF(rate_id, '')::bigint, NULLIF(reason, '') FROM updated
+			  RETURNING id, car_id, old_price_kzt, new_price_kzt, exchange_rate_id, reason, changed_at`
+	rows, err := r.DB.Query(query, ids, prices, rateIDs, reasons)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c domain.PriceChange
+		var oldPrice sql.NullFloat64
+		var rateID, reason sql.NullString
+		if err := rows.Scan(&c.ID, &c.CarID, &oldPrice, &c.NewPriceKZT, &rateID, &reason, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		c.OldPriceKZT, c.ExchangeRateID, c.Reason = oldPrice.Float64, rateID.String, reason.String
+		applied = append(applied, c)
+	}
+	return applied, rows.Err()
+}
+
+// UnlockPrice releases a manual price override so the worker reprices the car again.
+func (r *PostgresRepo) UnlockPrice(carID string) error {
+	res, err := r.DB.Exec(`UPDATE cars SET price_locked = FALSE, price_locked_until = NULL,
+						   version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, carID)
+	return expectOneRow(res, err, domain.ErrCarNotFound)
+}
+
+// GetPriceHistory lists a car's KZT price changes, newest first.
+func (r *PostgresRepo) GetPriceHistory(carID string) ([]domain.PriceChange, error) {
+	query := `SELECT id, car_id, old_price_kzt, new_price_kzt, exchange_rate_id, reason, changed_by, changed_at
+			  FROM price_changes WHERE car_id = $1 ORDER BY changed_at DESC, id DESC`
+	rows, err := r.DB.Query(query, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []domain.PriceChange{}
+	for rows.Next() {
+		var c domain.PriceChange
+		var rateID, reason, changedBy sql.NullString
+		if err := rows.Scan(&c.ID, &c.CarID, &c.OldPriceKZT, &c.NewPriceKZT, &rateID, &reason, &changedBy, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		c.ExchangeRateID, c.Reason, c.ChangedBy = rateID.String, reason.String, changedBy.String
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// SaveExchangeRate stores a fetched rate and fills in its ID.
+func (r *PostgresRepo) SaveExchangeRate(rate *domain.ExchangeRate) error {
+	if rate.Quote == "" {
+		rate.Quote = domain.CurrencyKZT
+	}
+	query := `INSERT INTO exchange_rates (currency, quote_currency, rate, source, fetched_at)
+			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	return r.DB.QueryRow(query, rate.Currency, rate.Quote, rate.Rate, rate.Source, rate.FetchedAt).Scan(&rate.ID)
+}
+
+// GetExchangeRates returns stored rates matching q, newest first.
+func (r *PostgresRepo) GetExchangeRates(q domain.RateQuery) ([]domain.ExchangeRate, error) {
+	var conds []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Currency != "" {
+		conds = append(conds, "currency = "+arg(q.Currency))
+	}
+	if !q.From.IsZero() {
+		conds = append(conds, "fetched_at >= "+arg(q.From))
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, "fetched_at < "+arg(q.To))
+	}
+
+	query := "SELECT id, currency, quote_currency, rate, source, fetched_at FROM exchange_rates"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY fetched_at DESC, id DESC"
+	if q.Limit > 0 {
+		query += " LIMIT " + arg(q.Limit)
+	}
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []domain.ExchangeRate{}
+	for rows.Next() {
+		var rate domain.ExchangeRate
+		if err := rows.Scan(&rate.ID, &rate.Currency, &rate.Quote, &rate.Rate, &rate.Source, &rate.FetchedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// GetLatestRates returns the most recent stored rate per currency.
+func (r *PostgresRepo) GetLatestRates() (map[string]domain.ExchangeRate, error) {
+	rows, err := r.DB.Query(`SELECT DISTINCT ON (currency) id, currency, quote_currency, rate, source, fetched_at
+							 FROM exchange_rates ORDER BY currency, fetched_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := map[string]domain.ExchangeRate{}
+	for rows.Next() {
+		var rate domain.ExchangeRate
+		if err := rows.Scan(&rate.ID, &rate.Currency, &rate.Quote, &rate.Rate, &rate.Source, &rate.FetchedAt); err != nil {
+			return nil, err
+		}
+		rates[rate.Currency] = rate
+	}
+	return rates, rows.Err()
+}
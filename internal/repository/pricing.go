@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"Assignment3ADP/internal/domain"
+	"database/sql"
+)
+
+const pricingRuleColumns = `id, make, category, markup_percent, customs_fee_kzt, logistics_fee_kzt,
+	vat_percent, rounding_step_kzt, min_margin_kzt, updated_at`
+
+// GetPricingRules lists every pricing rule, catch-all rules first.
+func (r *PostgresRepo) GetPricingRules() ([]domain.PricingRule, error) {
+	rows, err := r.DB.Query("SELECT " + pricingRuleColumns + " FROM pricing_rules ORDER BY make NULLS FIRST, category NULLS FIRST")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []domain.PricingRule{}
+	for rows.Next() {
+		rule, err := scanPricingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// CreatePricingRule inserts a rule and fills in its ID.
+func (r *PostgresRepo) CreatePricingRule(rule *domain.PricingRule) error {
+	query := `INSERT INTO pricing_rules (make, category, markup_percent, customs_fee_kzt, logistics_fee_kzt,
+			  vat_percent, rounding_step_kzt, min_margin_kzt)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, updated_at`
+	err := r.DB.QueryRow(query, nullIfEmpty(rule.Make), nullIfEmpty(rule.Category), rule.MarkupPercent,
+		rule.CustomsFeeKZT, rule.LogisticsFeeKZT, rule.VATPercent, rule.RoundingStepKZT, rule.MinMarginKZT).
+		Scan(&rule.ID, &rule.UpdatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateRule
+	}
+	return err
+}
+
+// UpdatePricingRule replaces every field of an existing rule.
+func (r *PostgresRepo) UpdatePricingRule(rule *domain.PricingRule) error {
+	query := `UPDATE pricing_rules SET make = $2, category = $3, markup_percent = $4, customs_fee_kzt = $5,
+			  logistics_fee_kzt = $6, vat_percent = $7, rounding_step_kzt = $8, min_margin_kzt = $9,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 RETURNING updated_at`
+	err := r.DB.QueryRow(query, rule.ID, nullIfEmpty(rule.Make), nullIfEmpty(rule.Category), rule.MarkupPercent,
+		rule.CustomsFeeKZT, rule.LogisticsFeeKZT, rule.VATPercent, rule.RoundingStepKZT, rule.MinMarginKZT).
+		Scan(&rule.UpdatedAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return domain.ErrRuleNotFound
+	case isUniqueViolation(err):
+		return domain.ErrDuplicateRule
+	}
+	return err
+}
+
+// DeletePricingRule removes a rule.
+func (r *PostgresRepo) DeletePricingRule(id string) error {
+	res, err := r.DB.Exec("DELETE FROM pricing_rules WHERE id = $1", id)
+	return expectOneRow(res, err, domain.ErrRuleNotFound)
+}
+
+func scanPricingRule(row rowScanner) (*domain.PricingRule, error) {
+	var rule domain.PricingRule
+	var carMake, category sql.NullString
+	if err := row.Scan(&rule.ID, &carMake, &category, &rule.MarkupPercent, &rule.CustomsFeeKZT, &rule.LogisticsFeeKZT,
+		&rule.VATPercent, &rule.RoundingStepKZT, &rule.MinMarginKZT, &rule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	rule.Make, rule.Category = carMake.String, category.String
+	return &rule, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"Assignment3ADP/internal/domain"
+	"html"
+	"strings"
+)
+
+// Headline delimiters that cannot appear in car text, swapped for <mark> tags after escaping.
+const (
+	headlineStart = "\x01"
+	headlineStop  = "\x02"
+)
+
+// extraScanner appends extra destinations after the carColumns ones.
+type extraScanner struct {
+	rowScanner
+	extra []interface{}
+}
+
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.rowScanner.Scan(append(dest, s.extra...)...)
+}
+
+// SearchCars ranks catalog cars against the search terms. Each term is matched as a
+// word prefix over make, model, VIN and description (search_vector); raw is also
+// compared by trigram similarity to "make model" to tolerate typos, and as a VIN prefix.
+func (r *PostgresRepo) SearchCars(terms []string, raw string, limit int) ([]domain.CarSearchHit, error) {
+	prefixes := make([]string, len(terms))
+	for i, t := range terms {
+		prefixes[i] = t + ":*"
+	}
+	tsquery := strings.Join(prefixes, " & ")
+
+	query := `SELECT ` + carColumns + `,
+				ts_rank(search_vector, q) + similarity(make || ' ' || model, $2) AS rank,
+				ts_headline('simple', make || ' ' || model || COALESCE(' - ' || description, ''), q,
+					'StartSel=` + headlineStart + `, StopSel=` + headlineStop + `, MaxFragments=2, MaxWords=25, MinWords=8') AS headline
+			  FROM cars, to_tsquery('simple', $1) AS q
+			  WHERE status IN ('available', 'transit')
+				AND (search_vector @@ q OR (make || ' ' || model) % $2 OR vin ILIKE $3)
+			  ORDER BY rank DESC, id
+			  LIMIT $4`
+	vinPrefix := escapeLike(strings.ToUpper(strings.ReplaceAll(raw, " ", ""))) + "%"
+
+	rows, err := r.DB.Query(query, tsquery, raw, vinPrefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []domain.CarSearchHit{}
+	for rows.Next() {
+		var hit domain.CarSearchHit
+		c, err := scanCar(extraScanner{rows, []interface{}{&hit.Rank, &hit.Headline}})
+		if err != nil {
+			return nil, err
+		}
+		hit.Car = *c
+		hit.Headline = markHeadline(hit.Headline)
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// markHeadline HTML-escapes a ts_headline excerpt and turns the delimiters into <mark> tags.
+func markHeadline(s string) string {
+	s = html.EscapeString(s)
+	return strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>").Replace(s)
+}
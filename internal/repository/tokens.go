@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"Assignment3ADP/internal/domain"
+	"database/sql"
+	"time"
+)
+
+// CreateRefreshToken stores a freshly issued refresh token.
+func (r *PostgresRepo) CreateRefreshToken(t *domain.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, token_hash, access_token_id, access_expires_at, expires_at)
+			  VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	return r.DB.QueryRow(query, t.UserID, t.TokenHash, t.AccessTokenID, t.AccessExpiresAt, t.ExpiresAt).
+		Scan(&t.ID, &t.CreatedAt)
+}
+
+// GetRefreshTokenByHash looks a refresh token up by the hash of its value.
+func (r *PostgresRepo) GetRefreshTokenByHash(hash string) (*domain.RefreshToken, error) {
+	t := &domain.RefreshToken{}
+	var revokedAt sql.NullTime
+	query := `SELECT id, user_id, token_hash, access_token_id, access_expires_at, expires_at, created_at, revoked_at
+			  FROM refresh_tokens WHERE token_hash = $1`
+	err := r.DB.QueryRow(query, hash).
+		Scan(&t.ID, &t.UserID, &t.TokenHash, &t.AccessTokenID, &t.AccessExpiresAt, &t.ExpiresAt, &t.CreatedAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	return t, nil
+}
+
+// RotateRefreshToken replaces a refresh token with its successor in a single transaction.
+// It fails with ErrTokenRevoked if the old token was already used concurrently.
+func (r *PostgresRepo) RotateRefreshToken(oldID string, next *domain.RefreshToken) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insert := `INSERT INTO refresh_tokens (user_id, token_hash, access_token_id, access_expires_at, expires_at)
+			   VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	if err := tx.QueryRow(insert, next.UserID, next.TokenHash, next.AccessTokenID, next.AccessExpiresAt, next.ExpiresAt).
+		Scan(&next.ID, &next.CreatedAt); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP, replaced_by = $2
+						 WHERE id = $1 AND revoked_at IS NULL`, oldID, next.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrTokenRevoked
+	}
+	return tx.Commit()
+}
+
+// RevokeRefreshToken invalidates a single refresh token (logout).
+func (r *PostgresRepo) RevokeRefreshToken(hash string) error {
+	_, err := r.DB.Exec("UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE token_hash = $1 AND revoked_at IS NULL", hash)
+	return err
+}
+
+// RevokeAccessToken puts an access token's jti on the revocation list until it expires.
+func (r *PostgresRepo) RevokeAccessToken(jti string, userID string, expiresAt time.Time) error {
+	_, err := r.DB.Exec(`INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3)
+						 ON CONFLICT (jti) DO NOTHING`, jti, userID, expiresAt)
+	return err
+}
+
+// IsAccessTokenRevoked checks the revocation list for a jti.
+func (r *PostgresRepo) IsAccessTokenRevoked(jti string) (bool, error) {
+	var revoked bool
+	err := r.DB.QueryRow("SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)", jti).Scan(&revoked)
+	return revoked, err
+}
+
+// RevokeAllUserSessions revokes every refresh token of a user together with
+// the access tokens issued alongside them that have not expired yet.
+func (r *PostgresRepo) RevokeAllUserSessions(userID string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO revoked_tokens (jti, user_id, expires_at)
+						  SELECT access_token_id, user_id, access_expires_at FROM refresh_tokens
+						  WHERE user_id = $1 AND access_expires_at > CURRENT_TIMESTAMP
+						  ON CONFLICT (jti) DO NOTHING`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL", userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"Assignment3ADP/internal/domain"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ListUsers returns every account, newest first.
+func (r *PostgresRepo) ListUsers() ([]domain.User, error) {
+	rows, err := r.DB.Query("SELECT id, username, COALESCE(email, ''), role, disabled, created_at FROM users ORDER BY created_at DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		var u domain.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role, &u.Disabled, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// UpdateUserRole changes a user's role.
+func (r *PostgresRepo) UpdateUserRole(id string, role string) error {
+	res, err := r.DB.Exec("UPDATE users SET role = $1 WHERE id = $2", role, id)
+	return expectOneRow(res, err, domain.ErrUserNotFound)
+}
+
+// SetUserDisabled enables or disables login for a user.
+func (r *PostgresRepo) SetUserDisabled(id string, disabled bool) error {
+	res, err := r.DB.Exec("UPDATE users SET disabled = $1 WHERE id = $2", disabled, id)
+	return expectOneRow(res, err, domain.ErrUserNotFound)
+}
+
+// UpdatePassword stores a new bcrypt hash for a user.
+func (r *PostgresRepo) UpdatePassword(id string, passwordHash string) error {
+	res, err := r.DB.Exec("UPDATE users SET password_hash = $1 WHERE id = $2", passwordHash, id)
+	return expectOneRow(res, err, domain.ErrUserNotFound)
+}
+
+// UpdateEmail sets or clears (with "") the address used for notifications.
+func (r *PostgresRepo) UpdateEmail(id string, email string) error {
+	res, err := r.DB.Exec("UPDATE users SET email = $1 WHERE id = $2", nullIfEmpty(email), id)
+	return expectOneRow(res, err, domain.ErrUserNotFound)
+}
+
+// expectOneRow turns an UPDATE that matched nothing into notFound.
+func expectOneRow(res sql.Result, err error, notFound error) error {
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique_violation (23505).
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isForeignKeyViolation reports whether err is a PostgreSQL foreign_key_violation (23503).
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
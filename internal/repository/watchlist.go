@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"Assignment3ADP/internal/domain"
+)
+
+// WatchCar subscribes a user to a car, starting from its current price and status.
+// Watching a car twice keeps the original subscription.
+func (r *PostgresRepo) WatchCar(userID string, carID string) error {
+	query := `INSERT INTO watchlist (user_id, car_id, baseline_price_kzt, last_status)
+			  SELECT $1, id, COALESCE(price_kzt, 0), status FROM cars WHERE id = $2
+			  ON CONFLICT (user_id, car_id) DO NOTHING`
+	if _, err := r.DB.Exec(query, userID, carID); err != nil {
+		return err
+	}
+	// The insert is a no-op both for a missing car and for a repeat watch.
+	_, err := r.GetCarByID(carID)
+	return err
+}
+
+// UnwatchCar removes a car from a user's watchlist.
+func (r *PostgresRepo) UnwatchCar(userID string, carID string) error {
+	res, err := r.DB.Exec("DELETE FROM watchlist WHERE user_id = $1 AND car_id = $2", userID, carID)
+	return expectOneRow(res, err, domain.ErrNotWatching)
+}
+
+// GetWatchlist lists the cars a user is watching.
+func (r *PostgresRepo) GetWatchlist(userID string) ([]domain.Car, error) {
+	return r.fetchCars("SELECT "+carColumns+" FROM cars WHERE id IN (SELECT car_id FROM watchlist WHERE user_id = $1) ORDER BY make, model", userID)
+}
+
+// GetWatches returns every subscription of an enabled user together with the car's current state.
+func (r *PostgresRepo) GetWatches() ([]domain.Watch, error) {
+	query := `SELECT w.user_id, u.username, COALESCE(u.email, ''), w.car_id, c.make, c.model,
+			  COALESCE(c.price_kzt, 0), c.status, w.baseline_price_kzt, w.last_status
+			  FROM watchlist w
+			  JOIN users u ON u.id = w.user_id
+			  JOIN cars c ON c.id = w.car_id
+			  WHERE NOT u.disabled`
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	watches := []domain.Watch{}
+	for rows.Next() {
+		var w domain.Watch
+		if err := rows.Scan(&w.UserID, &w.Username, &w.Email, &w.CarID, &w.CarMake, &w.CarModel,
+			&w.PriceKZT, &w.Status, &w.BaselinePriceKZT, &w.LastStatus); err != nil {
+			return nil, err
+		}
+		watches = append(watches, w)
+	}
+	return watches, rows.Err()
+}
+
+// UpdateWatch stores the price and status last reported to the watcher.
+func (r *PostgresRepo) UpdateWatch(w domain.Watch) error {
+	res, err := r.DB.Exec("UPDATE watchlist SET baseline_price_kzt = $3, last_status = $4 WHERE user_id = $1 AND car_id = $2",
+		w.UserID, w.CarID, w.BaselinePriceKZT, w.LastStatus)
+	return expectOneRow(res, err, domain.ErrNotWatching)
+}
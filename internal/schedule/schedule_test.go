@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+var almaty = time.FixedZone("UTC+5", 5*60*60)
+
+func at(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, almaty)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	cases := []struct {
+		expr, from, want string
+	}{
+		{"0 9 * * *", "2026-03-10 08:30", "2026-03-10 09:00"},
+		{"0 9 * * *", "2026-03-10 09:00", "2026-03-11 09:00"},
+		{"*/15 * * * *", "2026-03-10 10:07", "2026-03-10 10:15"},
+		{"*/15 * * * *", "2026-03-10 10:50", "2026-03-10 11:00"},
+		{"30 8-18/2 * * *", "2026-03-10 09:00", "2026-03-10 10:30"},
+		{"0 0 * * 1-5", "2026-03-14 12:00", "2026-03-16 00:00"},  // Saturday -> Monday
+		{"0 0 * * 7", "2026-03-14 12:00", "2026-03-15 00:00"},    // 7 is Sunday
+		{"0 0 1,15 * 1", "2026-03-10 00:00", "2026-03-15 00:00"}, // either day field may match
+		{"0 0 1,15 * 1", "2026-03-02 00:00", "2026-03-09 00:00"},
+		{"0 0 1 * *", "2026-12-15 00:00", "2027-01-01 00:00"},
+		{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"@daily", "2026-03-10 23:59", "2026-03-11 00:00"},
+		{"@hourly", "2026-03-10 10:00", "2026-03-10 11:00"},
+	}
+	for _, tc := range cases {
+		s, err := Parse(tc.expr, almaty)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.expr, err)
+		}
+		if got, want := s.Next(at(tc.from)), at(tc.want); !got.Equal(want) {
+			t.Errorf("%q.Next(%s) = %s, want %s", tc.expr, tc.from, got, want)
+		}
+	}
+}
+
+func TestNextSkipsCurrentMinute(t *testing.T) {
+	s, err := Parse("0 9 * * *", almaty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Next(at("2026-03-10 09:00").Add(30*time.Second)), at("2026-03-11 09:00"); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	s, err := Parse("0 9 * * *", almaty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 03:30 UTC is 08:30 in UTC+5, so the next run is 09:00 local, 04:00 UTC.
+	got := s.Next(time.Date(2026, 3, 10, 3, 30, 0, 0, time.UTC))
+	if want := time.Date(2026, 3, 10, 4, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got.UTC(), want)
+	}
+	if got.Location() != almaty {
+		t.Errorf("Next location = %s, want %s", got.Location(), almaty)
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s, err := Parse("0 0 31 2 *", almaty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(at("2026-01-01 00:00")); !got.IsZero() {
+		t.Errorf("Next = %s, want zero time", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 9 * *",
+		"0 9 * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@yearly",
+	} {
+		if _, err := Parse(expr, almaty); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	s, err := Parse(" 0 9 * * * ", almaty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.String(); got != "0 9 * * * (UTC+5)" {
+		t.Errorf("String = %q", got)
+	}
+	if s, _ := Parse("@daily", nil); s.loc != time.Local {
+		t.Errorf("nil location = %s, want Local", s.loc)
+	}
+}
@@ -2,47 +2,353 @@ package service
 
 import (
 	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/notify"
+	"Assignment3ADP/internal/vin"
 	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
 )
 
 type AdminService struct {
-	Repo domain.Repository
+	Repo       domain.Repository
+	Rates      domain.ExchangeRateProvider
+	VINDecoder *vin.Decoder
+	Notifier   domain.Notifier
+
+	// PriceDropAlertPercent is how far (in percent) a watched car's price must fall
+	// below the last reported price before its watchers are notified.
+	PriceDropAlertPercent float64
+
+	currencyJob *currencyJob
+}
+
+func NewAdminService(repo domain.Repository, rates domain.ExchangeRateProvider) *AdminService {
+	return &AdminService{
+		Repo:       repo,
+		Rates:      rates,
+		VINDecoder: vin.NewDecoder(vin.DefaultTable()),
+		Notifier:   notify.NewLogNotifier(),
+
+		PriceDropAlertPercent: defaultPriceDropAlertPercent,
+
+		currencyJob: newCurrencyJob(),
+	}
 }
 
-func NewAdminService(repo domain.Repository) *AdminService {
-	return &AdminService{Repo: repo}
+// DecodeVIN reads manufacturer, country and model year from a VIN.
+func (s *AdminService) DecodeVIN(raw string) (*vin.Decoded, error) {
+	return s.VINDecoder.Decode(raw)
 }
 
-// CreateCar now accepts imageURL.
-func (s *AdminService) CreateCar(vin, model, imageURL string, priceUSD float64) error {
-	if priceUSD <= 0 {
-		return errors.New("price must be positive")
+// CreateCar validates a new vehicle and adds it to the inventory in transit.
+// Make and year are pre-filled from the VIN when the caller leaves them empty.
+func (s *AdminService) CreateCar(c *domain.Car) error {
+	if decoded, err := s.VINDecoder.Decode(c.VIN); err == nil {
+		if strings.TrimSpace(c.Make) == "" {
+			c.Make = decoded.Make
+		}
+		if c.Year == 0 {
+			c.Year = decoded.ModelYear
+		}
+	}
+
+	if err := validateNewCar(c); err != nil {
+		return err
 	}
 
-	newCar := &domain.Car{
-		VIN:      vin,
-		Model:    model,
-		PriceUSD: priceUSD,
-		Status:   "transit",
-		ImageURL: imageURL, // Set the URL
+	priceUSD, err := s.convertToUSD(c.PurchasePrice, c.PurchaseCurrency)
+	if err != nil {
+		return err
 	}
+	c.PriceUSD = priceUSD
 
-	return s.Repo.CreateCar(newCar)
+	c.Status = domain.StatusTransit
+	return s.Repo.CreateCar(c)
 }
 
-// UpdatePrice updates car price by id
-func (s *AdminService) UpdatePrice(id string, newPriceKZT float64) error {
-	return s.Repo.UpdatePrice(id, newPriceKZT)
+// convertToUSD derives the USD cost of a purchase through the latest stored KZT rates.
+func (s *AdminService) convertToUSD(amount float64, currency string) (float64, error) {
+	if currency == "USD" {
+		return amount, nil
+	}
+
+	rates, err := s.Repo.GetLatestRates()
+	if err != nil {
+		return 0, err
+	}
+	from, ok := rates[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", domain.ErrRateUnavailable, currency)
+	}
+	usd, ok := rates["USD"]
+	if !ok {
+		return 0, fmt.Errorf("%w: USD", domain.ErrRateUnavailable)
+	}
+	return math.Round(amount*from.Rate/usd.Rate*100) / 100, nil
+}
+
+// OverridePrice sets a car's KZT price by hand and locks it against the currency
+// worker until lockedUntil, or until UnlockPrice when lockedUntil is nil.
+func (s *AdminService) OverridePrice(id string, priceKZT float64, lockedUntil *time.Time, reason, actorID string) (*domain.PriceChange, error) {
+	v := &domain.ValidationError{}
+	reason = strings.TrimSpace(reason)
+	if priceKZT <= 0 {
+		v.Add("price_kzt", "must be positive")
+	}
+	checkText(v, "reason", reason, 500, true)
+	if lockedUntil != nil && !lockedUntil.After(time.Now()) {
+		v.Add("locked_until", "must be in the future")
+	}
+	if err := v.OrNil(); err != nil {
+		return nil, err
+	}
+
+	change := &domain.PriceChange{
+		CarID:       id,
+		NewPriceKZT: priceKZT,
+		Reason:      "manual override: " + reason,
+		ChangedBy:   actorID,
+		Lock:        true,
+		LockedUntil: lockedUntil,
+	}
+	if err := s.Repo.UpdatePrice(change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// UnlockPrice hands a car's price back to the currency worker.
+func (s *AdminService) UnlockPrice(id string) error {
+	return s.Repo.UnlockPrice(id)
+}
+
+// GetPriceHistory returns a car's KZT price changes with the rates behind them.
+func (s *AdminService) GetPriceHistory(id string) ([]domain.PriceChange, error) {
+	if _, err := s.Repo.GetCarByID(id); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetPriceHistory(id)
+}
+
+// UpdateCar validates and applies a partial edit, guarded by the version the caller last saw.
+func (s *AdminService) UpdateCar(id string, patch domain.CarPatch, expectedVersion int) (*domain.Car, error) {
+	if err := validateCarPatch(&patch); err != nil {
+		return nil, err
+	}
+	if patch.PriceUSD != nil || patch.PurchasePrice != nil || patch.PurchaseCurrency != nil {
+		if err := s.resolvePurchasePrice(id, &patch); err != nil {
+			return nil, err
+		}
+	}
+	return s.Repo.UpdateCar(id, patch, expectedVersion)
+}
+
+// resolvePurchasePrice completes a price edit from the stored car: it fills in
+// whichever of purchase price and currency was left out and recomputes price_usd
+// from them. A bare price_usd is only accepted for cars bought in USD, where it
+// is the purchase price.
+func (s *AdminService) resolvePurchasePrice(id string, p *domain.CarPatch) error {
+	car, err := s.Repo.GetCarByID(id)
+	if err != nil {
+		return err
+	}
+
+	if p.PurchasePrice == nil && p.PurchaseCurrency == nil {
+		if car.PurchaseCurrency != "USD" {
+			v := &domain.ValidationError{}
+			v.Add("price_usd", fmt.Sprintf("car is bought in %s; set purchase_price instead", car.PurchaseCurrency))
+			return v
+		}
+		return nil
+	}
+
+	if p.PurchasePrice == nil {
+		p.PurchasePrice = &car.PurchasePrice
+	}
+	if p.PurchaseCurrency == nil {
+		p.PurchaseCurrency = &car.PurchaseCurrency
+	}
+	priceUSD, err := s.convertToUSD(*p.PurchasePrice, *p.PurchaseCurrency)
+	if err != nil {
+		return err
+	}
+	p.PriceUSD = &priceUSD
+	return nil
+}
+
+// validateNewCar normalizes a car's fields in place and checks them.
+func validateNewCar(c *domain.Car) error {
+	v := &domain.ValidationError{}
+
+	c.VIN = vin.Normalize(c.VIN)
+	c.Make = strings.TrimSpace(c.Make)
+	c.Model = strings.TrimSpace(c.Model)
+	c.Category = strings.ToLower(strings.TrimSpace(c.Category))
+	c.Color = strings.TrimSpace(c.Color)
+	c.Location = strings.TrimSpace(c.Location)
+	c.Description = strings.TrimSpace(c.Description)
+	c.PurchaseCurrency = strings.ToUpper(strings.TrimSpace(c.PurchaseCurrency))
+	if c.PurchaseCurrency == "" {
+		c.PurchaseCurrency = "USD"
+	}
+
+	checkVIN(v, c.VIN)
+	checkText(v, "make", c.Make, 50, true)
+	checkText(v, "model", c.Model, 50, true)
+	checkText(v, "category", c.Category, 30, false)
+	checkText(v, "color", c.Color, 30, false)
+	checkText(v, "location", c.Location, 100, false)
+	checkText(v, "description", c.Description, 2000, false)
+	checkText(v, "image_url", c.ImageURL, 255, false)
+	if c.Year != 0 {
+		checkYear(v, c.Year)
+	}
+	if c.Mileage < 0 {
+		v.Add("mileage", "must not be negative")
+	}
+	if c.PurchasePrice <= 0 {
+		v.Add("price", "must be positive")
+	}
+	if !domain.IsSupportedCurrency(c.PurchaseCurrency) {
+		v.Add("currency", "must be one of "+strings.Join(domain.SupportedCurrencies, ", "))
+	}
+
+	return v.OrNil()
+}
+
+// validateCarPatch normalizes string fields in place and checks every field that is set.
+func validateCarPatch(p *domain.CarPatch) error {
+	v := &domain.ValidationError{}
+
+	if p.VIN == nil && p.Make == nil && p.Model == nil && p.Category == nil && p.Year == nil && p.Mileage == nil &&
+		p.Color == nil && p.Location == nil && p.Description == nil && p.PriceUSD == nil && p.ImageURL == nil &&
+		p.PurchasePrice == nil && p.PurchaseCurrency == nil {
+		v.Add("body", "at least one field must be provided")
+	}
+
+	trim := func(value *string) {
+		if value != nil {
+			*value = strings.TrimSpace(*value)
+		}
+	}
+	trim(p.Make)
+	trim(p.Model)
+	trim(p.Color)
+	if p.Category != nil {
+		*p.Category = strings.ToLower(strings.TrimSpace(*p.Category))
+	}
+	trim(p.Location)
+	trim(p.Description)
+
+	if p.VIN != nil {
+		*p.VIN = vin.Normalize(*p.VIN)
+		checkVIN(v, *p.VIN)
+	}
+	if p.Make != nil {
+		checkText(v, "make", *p.Make, 50, true)
+	}
+	if p.Model != nil {
+		checkText(v, "model", *p.Model, 50, true)
+	}
+	if p.Category != nil {
+		checkText(v, "category", *p.Category, 30, false)
+	}
+	if p.Color != nil {
+		checkText(v, "color", *p.Color, 30, false)
+	}
+	if p.Location != nil {
+		checkText(v, "location", *p.Location, 100, false)
+	}
+	if p.Description != nil {
+		checkText(v, "description", *p.Description, 2000, false)
+	}
+	if p.ImageURL != nil {
+		checkText(v, "image_url", *p.ImageURL, 255, false)
+	}
+	if p.Year != nil {
+		checkYear(v, *p.Year)
+	}
+	if p.Mileage != nil && *p.Mileage < 0 {
+		v.Add("mileage", "must not be negative")
+	}
+	if p.PriceUSD != nil && *p.PriceUSD <= 0 {
+		v.Add("price_usd", "must be positive")
+	}
+	if p.PriceUSD != nil && (p.PurchasePrice != nil || p.PurchaseCurrency != nil) {
+		v.Add("price_usd", "is derived from purchase_price and purchase_currency; do not send both")
+	}
+	if p.PurchasePrice != nil && *p.PurchasePrice <= 0 {
+		v.Add("purchase_price", "must be positive")
+	}
+	if p.PurchaseCurrency != nil {
+		*p.PurchaseCurrency = strings.ToUpper(strings.TrimSpace(*p.PurchaseCurrency))
+		if !domain.IsSupportedCurrency(*p.PurchaseCurrency) {
+			v.Add("purchase_currency", "must be one of "+strings.Join(domain.SupportedCurrencies, ", "))
+		}
+	}
+
+	return v.OrNil()
+}
+
+func checkVIN(v *domain.ValidationError, value string) {
+	if value == "" {
+		v.Add("vin", "is required")
+		return
+	}
+	err := vin.Validate(value)
+	if errors.Is(err, vin.ErrCheckDigit) && !vin.CheckDigitRequired(value) {
+		err = nil
+	}
+	if err != nil {
+		v.Add("vin", err.Error())
+	}
+}
+
+func checkText(v *domain.ValidationError, field, value string, maxLen int, required bool) {
+	switch {
+	case required && value == "":
+		v.Add(field, "is required")
+	case len(value) > maxLen:
+		v.Add(field, fmt.Sprintf("must be at most %d characters", maxLen))
+	}
+}
+
+func checkYear(v *domain.ValidationError, year int) {
+	if maxYear := time.Now().Year() + 1; year < 1981 || year > maxYear {
+		v.Add("year", fmt.Sprintf("must be between 1981 and %d", maxYear))
+	}
 }
 
 func (s *AdminService) GetAllInventory() ([]domain.Car, error) {
 	return s.Repo.GetAllCars()
 }
 
+// GetCar returns the full record of a car, including cost and version, for editing.
+func (s *AdminService) GetCar(id string) (*domain.Car, error) {
+	return s.Repo.GetCarByID(id)
+}
+
 func (s *AdminService) DeleteCar(id string) error {
 	return s.Repo.DeleteCar(id)
 }
 
-func (s *AdminService) UpdateStatus(id string, status string) error {
-	return s.Repo.UpdateStatus(id, status)
+// UpdateStatus moves a car through its lifecycle on behalf of actorID.
+// Invalid transitions fail with a *domain.StatusTransitionError.
+func (s *AdminService) UpdateStatus(id, status, actorID, reason string) error {
+	return s.Repo.UpdateStatus(domain.StatusChange{
+		CarID:   id,
+		To:      status,
+		ActorID: actorID,
+		Reason:  strings.TrimSpace(reason),
+	})
+}
+
+// GetStatusHistory returns the status timeline of a car.
+func (s *AdminService) GetStatusHistory(id string) ([]domain.CarStatusEvent, error) {
+	if _, err := s.Repo.GetCarByID(id); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetCarStatusHistory(id)
 }
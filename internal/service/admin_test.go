@@ -0,0 +1,46 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"errors"
+	"testing"
+)
+
+func newTestAdminService(repo *fakeRepo) *AdminService {
+	return NewAdminService(repo, nil)
+}
+
+func TestCreateCarVINCheckDigit(t *testing.T) {
+	repo := newFakeRepo()
+	repo.rates["USD"] = domain.ExchangeRate{Currency: "USD", Rate: 500}
+	repo.rates["EUR"] = domain.ExchangeRate{Currency: "EUR", Rate: 550}
+	s := newTestAdminService(repo)
+
+	// European VINs usually carry no check digit; this one is a 2003 Golf
+	// bought in euros.
+	car := &domain.Car{VIN: "wvwzzz1jz3w386752", Model: "Golf", PurchasePrice: 15000, PurchaseCurrency: "EUR"}
+	if err := s.CreateCar(car); err != nil {
+		t.Fatalf("CreateCar with a European VIN: %v", err)
+	}
+	stored, err := repo.GetCarByID(car.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.VIN != "WVWZZZ1JZ3W386752" || stored.Make != "Volkswagen" || stored.Year != 2003 {
+		t.Errorf("stored car = %s %s %d, want WVWZZZ1JZ3W386752 Volkswagen 2003", stored.VIN, stored.Make, stored.Year)
+	}
+	if stored.PriceUSD != 16500 || stored.Status != domain.StatusTransit {
+		t.Errorf("stored car price_usd = %v, status = %s; want 16500, transit", stored.PriceUSD, stored.Status)
+	}
+
+	// North American VINs must carry a valid check digit.
+	err = s.CreateCar(&domain.Car{VIN: "1HGCM82643A004352", Model: "Accord", PurchasePrice: 20000})
+	var v *domain.ValidationError
+	if !errors.As(err, &v) || v.Fields["vin"] == "" {
+		t.Fatalf("CreateCar with a wrong North American check digit = %v, want a vin field error", err)
+	}
+
+	if err := s.CreateCar(&domain.Car{VIN: "1HGCM82633A004352", Model: "Accord", PurchasePrice: 20000}); err != nil {
+		t.Errorf("CreateCar with a valid North American VIN: %v", err)
+	}
+}
@@ -3,16 +3,32 @@ package service
 import (
 	"Assignment3ADP/internal/domain"
 	"errors"
+	"log"
+	"os"
+	"time"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
-// AuthService handles secure login and registration.
+// AuthService handles secure login, registration and token sessions.
 type AuthService struct {
-	Repo domain.Repository
+	Repo            domain.Repository
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	jwtKey          []byte
 }
 
 func NewAuthService(repo domain.Repository) *AuthService {
-	return &AuthService{Repo: repo}
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		log.Println("[WARNING] JWT_SECRET is empty in AuthService!")
+	}
+	return &AuthService{
+		Repo:            repo,
+		AccessTokenTTL:  durationFromEnv("ACCESS_TOKEN_TTL", defaultAccessTokenTTL),
+		RefreshTokenTTL: durationFromEnv("REFRESH_TOKEN_TTL", defaultRefreshTokenTTL),
+		jwtKey:          []byte(secret),
+	}
 }
 
 // Register hashes the password using Bcrypt before saving.
@@ -43,5 +59,9 @@ func (s *AuthService) Login(username, password string) (*domain.User, error) {
 		return nil, errors.New("invalid credentials")
 	}
 
+	if user.Disabled {
+		return nil, domain.ErrUserDisabled
+	}
+
 	return user, nil
 }
@@ -2,6 +2,10 @@ package service
 
 import (
 	"Assignment3ADP/internal/domain"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
 )
 
 type ClientService struct {
@@ -12,9 +16,23 @@ func NewClientService(repo domain.Repository) *ClientService {
 	return &ClientService{Repo: repo}
 }
 
-// GetCatalog returns only cars that customers are allowed to buy.
-func (s *ClientService) GetCatalog() ([]domain.Car, error) {
-	return s.Repo.GetAvailableCars()
+// CatalogStatuses are the statuses customers may see in the catalog.
+var CatalogStatuses = []string{domain.StatusAvailable, domain.StatusTransit}
+
+// GetCatalog returns one page of the cars customers are allowed to buy and the total match count.
+// Asking for a status outside CatalogStatuses is a validation error.
+func (s *ClientService) GetCatalog(f domain.CarFilter) ([]domain.Car, int, error) {
+	if len(f.Statuses) == 0 {
+		f.Statuses = CatalogStatuses
+	}
+	for _, status := range f.Statuses {
+		if !slices.Contains(CatalogStatuses, status) {
+			v := &domain.ValidationError{}
+			v.Add("status", "must be one of: "+strings.Join(CatalogStatuses, ", "))
+			return nil, 0, v
+		}
+	}
+	return s.Repo.FindCars(f)
 }
 
 // GetCarDetails fetches a specific car by its UUID.
@@ -29,9 +47,67 @@ func (s *ClientService) BookTestDrive(carID string, userID string) error {
 		return err
 	}
 
-	if car.Status != "available" && car.Status != "transit" {
+	if domain.ValidateStatusTransition(car.Status, domain.StatusReserved) != nil {
 		return domain.ErrCarNotAvailable
 	}
 
 	return s.Repo.BookCar(carID, userID)
 }
+
+// GetMyBookings returns the cars reserved under the given user's account.
+func (s *ClientService) GetMyBookings(userID string) ([]domain.Car, error) {
+	return s.Repo.GetCarsByUser(userID)
+}
+
+// GetExchangeRates returns the stored exchange-rate history.
+func (s *ClientService) GetExchangeRates(q domain.RateQuery) ([]domain.ExchangeRate, error) {
+	return s.Repo.GetExchangeRates(q)
+}
+
+// GetLatestRate returns the most recent stored rate of currency against KZT.
+func (s *ClientService) GetLatestRate(currency string) (*domain.ExchangeRate, error) {
+	rates, err := s.Repo.GetLatestRates()
+	if err != nil {
+		return nil, err
+	}
+	rate, ok := rates[currency]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRateUnavailable, currency)
+	}
+	return &rate, nil
+}
+
+// Search limits.
+const (
+	maxSearchQueryLength = 100
+	defaultSearchLimit   = 20
+	maxSearchLimit       = 50
+)
+
+// SearchCars runs a full-text search over the catalog. Terms are the letter and digit
+// runs of q, lower-cased; anything else in q only feeds the typo-tolerant match.
+func (s *ClientService) SearchCars(q string, limit int) ([]domain.CarSearchHit, error) {
+	q = strings.TrimSpace(q)
+	terms := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	v := &domain.ValidationError{}
+	switch {
+	case len(terms) == 0:
+		v.Add("q", "must contain at least one letter or digit")
+	case len(q) > maxSearchQueryLength:
+		v.Add("q", fmt.Sprintf("must be at most %d characters", maxSearchQueryLength))
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		v.Add("limit", fmt.Sprintf("must be between 1 and %d", maxSearchLimit))
+	}
+	if err := v.OrNil(); err != nil {
+		return nil, err
+	}
+
+	return s.Repo.SearchCars(terms, q, limit)
+}
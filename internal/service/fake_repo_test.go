@@ -0,0 +1,248 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"fmt"
+	"time"
+)
+
+// fakeRepo is an in-memory domain.Repository for service tests. It implements
+// the methods the tests exercise; calling any other one panics through the nil
+// embedded interface.
+type fakeRepo struct {
+	domain.Repository
+
+	cars  map[string]*domain.Car
+	rates map[string]domain.ExchangeRate
+
+	users         map[string]*domain.User
+	refreshTokens map[string]*domain.RefreshToken // by hash
+	revokedJTIs   map[string]bool
+
+	leads   map[string]*domain.Lead
+	touches map[string]int // lead ID -> inquiries recorded
+	// now is the fake database clock used by IntakeLead.
+	now time.Time
+	// beforeUpdateLead, when set, runs inside UpdateLead before the status check,
+	// e.g. to simulate another salesperson moving the lead first.
+	beforeUpdateLead func(lead *domain.Lead)
+
+	nextID int
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		cars:          map[string]*domain.Car{},
+		rates:         map[string]domain.ExchangeRate{},
+		users:         map[string]*domain.User{},
+		refreshTokens: map[string]*domain.RefreshToken{},
+		revokedJTIs:   map[string]bool{},
+		leads:         map[string]*domain.Lead{},
+		touches:       map[string]int{},
+		now:           time.Now(),
+	}
+}
+
+// newID returns a distinct UUID-shaped ID.
+func (r *fakeRepo) newID() string {
+	r.nextID++
+	return fakeUUID(r.nextID)
+}
+
+func (r *fakeRepo) CreateCar(c *domain.Car) error {
+	for _, existing := range r.cars {
+		if existing.VIN == c.VIN {
+			return domain.ErrDuplicateVIN
+		}
+	}
+	c.ID = r.newID()
+	c.Version = 1
+	stored := *c
+	r.cars[c.ID] = &stored
+	return nil
+}
+
+func (r *fakeRepo) addCar(vin, carMake, model string) *domain.Car {
+	c := &domain.Car{VIN: vin, Make: carMake, Model: model, Status: domain.StatusAvailable}
+	if err := r.CreateCar(c); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (r *fakeRepo) GetCarByID(id string) (*domain.Car, error) {
+	c, ok := r.cars[id]
+	if !ok {
+		return nil, domain.ErrCarNotFound
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (r *fakeRepo) addUser(username, role string) *domain.User {
+	u := &domain.User{ID: r.newID(), Username: username, Role: role}
+	r.users[u.ID] = u
+	return u
+}
+
+func (r *fakeRepo) GetUserByID(id string) (*domain.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (r *fakeRepo) CreateRefreshToken(t *domain.RefreshToken) error {
+	t.ID, t.CreatedAt = r.newID(), time.Now()
+	stored := *t
+	r.refreshTokens[t.TokenHash] = &stored
+	return nil
+}
+
+func (r *fakeRepo) GetRefreshTokenByHash(hash string) (*domain.RefreshToken, error) {
+	t, ok := r.refreshTokens[hash]
+	if !ok {
+		return nil, domain.ErrInvalidToken
+	}
+	copied := *t
+	return &copied, nil
+}
+
+func (r *fakeRepo) RotateRefreshToken(oldID string, next *domain.RefreshToken) error {
+	for _, t := range r.refreshTokens {
+		if t.ID != oldID {
+			continue
+		}
+		if t.RevokedAt != nil {
+			return domain.ErrTokenRevoked
+		}
+		now := time.Now()
+		t.RevokedAt = &now
+		return r.CreateRefreshToken(next)
+	}
+	return domain.ErrInvalidToken
+}
+
+func (r *fakeRepo) RevokeRefreshToken(hash string) error {
+	if t, ok := r.refreshTokens[hash]; ok && t.RevokedAt == nil {
+		now := time.Now()
+		t.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeRepo) RevokeAccessToken(jti string, userID string, expiresAt time.Time) error {
+	r.revokedJTIs[jti] = true
+	return nil
+}
+
+func (r *fakeRepo) IsAccessTokenRevoked(jti string) (bool, error) {
+	return r.revokedJTIs[jti], nil
+}
+
+func (r *fakeRepo) RevokeAllUserSessions(userID string) error {
+	now := time.Now()
+	for _, t := range r.refreshTokens {
+		if t.UserID != userID {
+			continue
+		}
+		if t.AccessExpiresAt.After(now) {
+			r.revokedJTIs[t.AccessTokenID] = true
+		}
+		if t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepo) addLead(status string) *domain.Lead {
+	l := &domain.Lead{ID: r.newID(), CustomerPhone: "+77011234567", InquiryType: domain.InquiryTestDrive, Status: status}
+	r.leads[l.ID] = l
+	return l
+}
+
+// IntakeLead follows the Postgres implementation: an inquiry is merged into the
+// customer's latest open lead of the same type about the same car when that
+// lead's last inquiry is within dedupWindow.
+func (r *fakeRepo) IntakeLead(lead *domain.Lead, dedupWindow time.Duration) (bool, error) {
+	var match *domain.Lead
+	for _, l := range r.leads {
+		if l.CustomerPhone != lead.CustomerPhone || l.Status == domain.LeadWon || l.Status == domain.LeadLost ||
+			l.CarID != lead.CarID || l.InquiryType != lead.InquiryType ||
+			l.LastInquiryAt.Before(r.now.Add(-dedupWindow)) {
+			continue
+		}
+		if match == nil || l.LastInquiryAt.After(match.LastInquiryAt) {
+			match = l
+		}
+	}
+
+	merged := match != nil
+	if merged {
+		match.Details = lead.Details
+		if lead.CustomerName != "" {
+			match.CustomerName = lead.CustomerName
+		}
+		match.LastInquiryAt, match.UpdatedAt = r.now, r.now
+	} else {
+		match = &domain.Lead{
+			ID:            r.newID(),
+			CarID:         lead.CarID,
+			CarModel:      lead.CarModel,
+			CustomerName:  lead.CustomerName,
+			CustomerPhone: lead.CustomerPhone,
+			InquiryType:   lead.InquiryType,
+			Details:       lead.Details,
+			Status:        lead.Status,
+			LastInquiryAt: r.now,
+			CreatedAt:     r.now,
+			UpdatedAt:     r.now,
+		}
+		r.leads[match.ID] = match
+	}
+	r.touches[match.ID]++
+	*lead = *match
+	return merged, nil
+}
+
+func (r *fakeRepo) GetLeadByID(id string) (*domain.Lead, error) {
+	l, ok := r.leads[id]
+	if !ok {
+		return nil, domain.ErrLeadNotFound
+	}
+	copied := *l
+	return &copied, nil
+}
+
+func (r *fakeRepo) UpdateLead(id string, patch domain.LeadPatch, expectedStatus string) (*domain.Lead, error) {
+	l, ok := r.leads[id]
+	if !ok {
+		return nil, domain.ErrLeadNotFound
+	}
+	if r.beforeUpdateLead != nil {
+		r.beforeUpdateLead(l)
+	}
+	if l.Status != expectedStatus {
+		return nil, domain.ErrLeadChanged
+	}
+	if patch.Status != nil {
+		l.Status = *patch.Status
+	}
+	if patch.AssignedUserID != nil {
+		l.AssignedUserID = *patch.AssignedUserID
+	}
+	l.UpdatedAt = time.Now()
+	return r.GetLeadByID(id)
+}
+
+func (r *fakeRepo) GetLatestRates() (map[string]domain.ExchangeRate, error) {
+	return r.rates, nil
+}
+
+// fakeUUID returns a distinct UUID-shaped ID for n.
+func fakeUUID(n int) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+}
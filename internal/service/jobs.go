@@ -0,0 +1,245 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/schedule"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCurrencySchedule = "0 9 * * *"
+	defaultCurrencyTimezone = "Asia/Almaty"
+)
+
+// CurrencyJobConfig controls when the currency worker runs and how it retries a failed run.
+type CurrencyJobConfig struct {
+	Schedule       *schedule.Schedule
+	RunOnStart     bool
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// CurrencyJobConfigFromEnv reads CURRENCY_SCHEDULE, CURRENCY_TIMEZONE, CURRENCY_RUN_ON_START,
+// CURRENCY_RETRY_ATTEMPTS and CURRENCY_RETRY_BACKOFF. A malformed schedule or time zone is an error.
+func CurrencyJobConfigFromEnv() (CurrencyJobConfig, error) {
+	tz := os.Getenv("CURRENCY_TIMEZONE")
+	if tz == "" {
+		tz = defaultCurrencyTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return CurrencyJobConfig{}, fmt.Errorf("CURRENCY_TIMEZONE: %w", err)
+	}
+
+	expr := os.Getenv("CURRENCY_SCHEDULE")
+	if expr == "" {
+		expr = defaultCurrencySchedule
+	}
+	sched, err := schedule.Parse(expr, loc)
+	if err != nil {
+		return CurrencyJobConfig{}, fmt.Errorf("CURRENCY_SCHEDULE: %w", err)
+	}
+
+	cfg := defaultCurrencyJobConfig()
+	cfg.Schedule = sched
+	cfg.InitialBackoff = durationFromEnv("CURRENCY_RETRY_BACKOFF", cfg.InitialBackoff)
+	if v, err := strconv.ParseBool(os.Getenv("CURRENCY_RUN_ON_START")); err == nil {
+		cfg.RunOnStart = v
+	}
+	if n, err := strconv.Atoi(os.Getenv("CURRENCY_RETRY_ATTEMPTS")); err == nil && n > 0 {
+		cfg.MaxAttempts = n
+	}
+	return cfg, nil
+}
+
+func defaultCurrencyJobConfig() CurrencyJobConfig {
+	return CurrencyJobConfig{
+		RunOnStart:     true,
+		MaxAttempts:    5,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     30 * time.Minute,
+	}
+}
+
+// CurrencyJobStatus is a snapshot of the currency worker.
+type CurrencyJobStatus struct {
+	Schedule       string     `json:"schedule,omitempty"`
+	Running        bool       `json:"running"`
+	Trigger        string     `json:"trigger,omitempty"` // "startup", "schedule" or "manual"
+	Attempts       int        `json:"attempts"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastOutcome    string     `json:"last_outcome,omitempty"` // "success" or "failed"
+	LastError      string     `json:"last_error,omitempty"`
+	UpdatedCars    int        `json:"updated_cars"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+}
+
+// currencyJob serializes runs of the currency worker and tracks their status.
+type currencyJob struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cfg    CurrencyJobConfig
+	status CurrencyJobStatus
+	manual sync.WaitGroup // runs started by TriggerCurrencyJob
+}
+
+func newCurrencyJob() *currencyJob {
+	return &currencyJob{ctx: context.Background(), cfg: defaultCurrencyJobConfig()}
+}
+
+// begin marks a run as started, or reports false if one is already in progress.
+func (j *currencyJob) begin(trigger string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status.Running {
+		return false
+	}
+	now := time.Now()
+	j.status.Running, j.status.Trigger, j.status.Attempts = true, trigger, 0
+	j.status.LastStartedAt = &now
+	return true
+}
+
+func (j *currencyJob) finish(updated int, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.status.Running = false
+	j.status.LastFinishedAt = &now
+	j.status.UpdatedCars = updated
+	j.status.LastOutcome, j.status.LastError = "success", ""
+	if err != nil {
+		j.status.LastOutcome, j.status.LastError = "failed", err.Error()
+	}
+}
+
+func (j *currencyJob) update(fn func(*CurrencyJobStatus)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(&j.status)
+}
+
+// StartCurrencyWorker runs the currency update on cfg.Schedule until ctx is cancelled.
+func (s *AdminService) StartCurrencyWorker(ctx context.Context, cfg CurrencyJobConfig) {
+	s.currencyJob.mu.Lock()
+	s.currencyJob.ctx, s.currencyJob.cfg = ctx, cfg
+	s.currencyJob.status.Schedule = cfg.Schedule.String()
+	s.currencyJob.mu.Unlock()
+
+	log.Printf("[Worker] Currency updater scheduled: %s", cfg.Schedule)
+	if cfg.RunOnStart {
+		log.Println("[Worker] Running initial startup update...")
+		s.runCurrencyJob("startup")
+	}
+
+	for {
+		next := cfg.Schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("[Worker Error] Schedule %s never fires, stopping", cfg.Schedule)
+			return
+		}
+		s.currencyJob.update(func(st *CurrencyJobStatus) { st.NextRunAt = &next })
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("[Worker] Currency updater stopped.")
+			return
+		case <-timer.C:
+		}
+
+		log.Println("[Worker] Waking up to update currency rates...")
+		s.runCurrencyJob("schedule")
+	}
+}
+
+// TriggerCurrencyJob starts a currency update in the background.
+// It fails with domain.ErrJobRunning while another run is in progress.
+func (s *AdminService) TriggerCurrencyJob() error {
+	if !s.currencyJob.begin("manual") {
+		return domain.ErrJobRunning
+	}
+	s.currencyJob.manual.Add(1)
+	go func() {
+		defer s.currencyJob.manual.Done()
+		s.executeCurrencyJob()
+	}()
+	return nil
+}
+
+// WaitForCurrencyJobs blocks until runs started by TriggerCurrencyJob have
+// finished, or ctx is done. Call it once no more requests can trigger a run.
+func (s *AdminService) WaitForCurrencyJobs(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.currencyJob.manual.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CurrencyJobStatus reports the last run and the next scheduled one.
+func (s *AdminService) CurrencyJobStatus() CurrencyJobStatus {
+	s.currencyJob.mu.Lock()
+	defer s.currencyJob.mu.Unlock()
+	return s.currencyJob.status
+}
+
+func (s *AdminService) runCurrencyJob(trigger string) {
+	if !s.currencyJob.begin(trigger) {
+		log.Printf("[Worker] Skipping %s run: previous run still in progress", trigger)
+		return
+	}
+	s.executeCurrencyJob()
+}
+
+// executeCurrencyJob performs one run that begin has already claimed, retrying
+// with exponential backoff until it succeeds, attempts run out or the worker stops.
+func (s *AdminService) executeCurrencyJob() {
+	s.currencyJob.mu.Lock()
+	ctx, cfg := s.currencyJob.ctx, s.currencyJob.cfg
+	s.currencyJob.mu.Unlock()
+
+	var updated int
+	var err error
+	backoff := cfg.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		s.currencyJob.update(func(st *CurrencyJobStatus) { st.Attempts = attempt })
+
+		updated, err = s.performDailyUpdate(ctx)
+		if err == nil || attempt >= cfg.MaxAttempts || errors.Is(err, context.Canceled) {
+			break
+		}
+
+		log.Printf("[Worker Error] Attempt %d/%d failed: %v. Retrying in %s", attempt, cfg.MaxAttempts, err, backoff)
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(backoff):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		backoff = min(backoff*2, cfg.MaxBackoff)
+	}
+
+	if err != nil {
+		log.Printf("[Worker Error] Currency update failed: %v", err)
+	}
+	s.currencyJob.finish(updated, err)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"Assignment3ADP/internal/antispam"
+	"Assignment3ADP/internal/domain"
+	"expvar"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// leadMetrics counts public lead submissions by outcome; published at /api/admin/metrics.
+var leadMetrics = expvar.NewMap("leads")
+
+// Lead intake outcomes recorded in leadMetrics.
+const (
+	leadAccepted          = "accepted"
+	leadMerged            = "merged"
+	leadRejectedHoneypot  = "rejected_honeypot"
+	leadRejectedToken     = "rejected_token"
+	leadRejectedRateIP    = "rejected_rate_ip"
+	leadRejectedRatePhone = "rejected_rate_phone"
+	leadRejectedInvalid   = "rejected_invalid"
+)
+
+// LeadSource describes where a public inquiry came from, for spam checks.
+type LeadSource struct {
+	IP        string
+	FormToken string
+	Honeypot  string // a form field hidden from people; only bots fill it in
+}
+
+// LeadGuard screens public lead submissions: a signed form token, a honeypot
+// field and rate limits per client IP and per phone number.
+type LeadGuard struct {
+	Tokens   *antispam.FormTokens
+	PerIP    *antispam.Limiter
+	PerPhone *antispam.Limiter
+}
+
+// LeadGuardFromEnv reads LEAD_FORM_SECRET, LEAD_TOKEN_MIN_AGE, LEAD_TOKEN_MAX_AGE,
+// LEAD_RATE_WINDOW, LEAD_RATE_PER_IP and LEAD_RATE_PER_PHONE.
+func LeadGuardFromEnv() (*LeadGuard, error) {
+	perIP, err := positiveIntFromEnv("LEAD_RATE_PER_IP", 10)
+	if err != nil {
+		return nil, err
+	}
+	perPhone, err := positiveIntFromEnv("LEAD_RATE_PER_PHONE", 3)
+	if err != nil {
+		return nil, err
+	}
+	window := durationFromEnv("LEAD_RATE_WINDOW", time.Hour)
+
+	return &LeadGuard{
+		Tokens: antispam.NewFormTokens([]byte(os.Getenv("LEAD_FORM_SECRET")),
+			durationFromEnv("LEAD_TOKEN_MIN_AGE", 3*time.Second),
+			durationFromEnv("LEAD_TOKEN_MAX_AGE", 2*time.Hour)),
+		PerIP:    antispam.NewLimiter(perIP, window),
+		PerPhone: antispam.NewLimiter(perPhone, window),
+	}, nil
+}
+
+func positiveIntFromEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", key, value)
+	}
+	return n, nil
+}
+
+// IssueFormToken returns a token for the public lead form.
+func (s *LeadService) IssueFormToken() (token string, expiresAt time.Time) {
+	now := time.Now()
+	if s.Guard == nil {
+		return "", now
+	}
+	return s.Guard.Tokens.Issue(now), now.Add(s.Guard.Tokens.MaxAge)
+}
+
+// screen runs the checks that need no lead data. Without a guard every
+// submission passes.
+func (s *LeadService) screen(src LeadSource, now time.Time) error {
+	if s.Guard == nil {
+		return nil
+	}
+	if src.Honeypot != "" {
+		leadMetrics.Add(leadRejectedHoneypot, 1)
+		return domain.ErrSpamDetected
+	}
+	if err := s.Guard.Tokens.Check(src.FormToken, now); err != nil {
+		leadMetrics.Add(leadRejectedToken, 1)
+		return fmt.Errorf("%w: %v", domain.ErrInvalidFormToken, err)
+	}
+	if ok, retry := s.Guard.PerIP.Allow(src.IP, now); !ok {
+		leadMetrics.Add(leadRejectedRateIP, 1)
+		return &domain.RateLimitError{Scope: "ip", RetryAfter: retry}
+	}
+	return nil
+}
+
+// admit uses up the form token and applies the per-phone limit to a validated lead.
+func (s *LeadService) admit(lead *domain.Lead, src LeadSource, now time.Time) error {
+	if s.Guard == nil {
+		return nil
+	}
+	if err := s.Guard.Tokens.Consume(src.FormToken, now); err != nil {
+		leadMetrics.Add(leadRejectedToken, 1)
+		return fmt.Errorf("%w: %v", domain.ErrInvalidFormToken, err)
+	}
+	if ok, retry := s.Guard.PerPhone.Allow(lead.CustomerPhone, now); !ok {
+		leadMetrics.Add(leadRejectedRatePhone, 1)
+		return &domain.RateLimitError{Scope: "phone", RetryAfter: retry}
+	}
+	return nil
+}
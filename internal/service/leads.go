@@ -0,0 +1,296 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const defaultLeadDedupWindow = 24 * time.Hour
+
+// Limits of the public lead form, matching the leads columns.
+const (
+	minLeadNameLength = 2
+	maxLeadNameLength = 100
+	maxLeadCarModel   = 50
+)
+
+// Limits of the inquiry details.
+const (
+	maxTestDriveDaysAhead = 60
+	minTradeInYear        = 1950
+	minFinancingTerm      = 6
+	maxFinancingTerm      = 96
+)
+
+// LeadService runs the sales pipeline: intake, stage changes, assignment and notes.
+type LeadService struct {
+	Repo domain.Repository
+	// DedupWindow is how long after a customer's last inquiry a new one from the
+	// same phone of the same type about the same car is merged into their open lead.
+	DedupWindow time.Duration
+	// Guard screens public submissions for spam; nil disables the checks.
+	Guard *LeadGuard
+}
+
+func NewLeadService(repo domain.Repository) *LeadService {
+	return &LeadService{
+		Repo:        repo,
+		DedupWindow: durationFromEnv("LEAD_DEDUP_WINDOW", defaultLeadDedupWindow),
+	}
+}
+
+// CreateLead validates a customer inquiry and records it at the start of the
+// pipeline. The phone is stored in E.164 form; a repeat inquiry of the same type
+// about the same car from the same phone within DedupWindow is merged into the
+// customer's open lead, which is reported by merged. When the inquiry names a
+// car, car_model is taken from the car itself; a free-text car_model is only
+// kept for inquiries that don't. Each inquiry type requires its own details,
+// e.g. a preferred date and time slot for a test drive. Submissions that fail
+// the Guard's checks are counted and never stored.
+func (s *LeadService) CreateLead(lead *domain.Lead, src LeadSource) (merged bool, err error) {
+	now := time.Now()
+	if err := s.screen(src, now); err != nil {
+		return false, err
+	}
+
+	lead.CarID = strings.TrimSpace(lead.CarID)
+	lead.CarModel = strings.TrimSpace(lead.CarModel)
+	lead.CustomerName = strings.Join(strings.Fields(lead.CustomerName), " ")
+
+	v := &domain.ValidationError{}
+	switch n := utf8.RuneCountInString(lead.CustomerName); {
+	case n == 0:
+		v.Add("name", "is required")
+	case n < minLeadNameLength || n > maxLeadNameLength:
+		v.Add("name", fmt.Sprintf("must be between %d and %d characters", minLeadNameLength, maxLeadNameLength))
+	case !strings.ContainsFunc(lead.CustomerName, unicode.IsLetter):
+		v.Add("name", "must contain letters")
+	}
+	if strings.TrimSpace(lead.CustomerPhone) == "" {
+		v.Add("phone", "is required")
+	} else if phone, ok := domain.NormalizePhone(lead.CustomerPhone); ok {
+		lead.CustomerPhone = phone
+	} else {
+		v.Add("phone", "must be a valid phone number, e.g. +7 701 123 45 67")
+	}
+	if lead.CarID == "" && lead.CarModel == "" {
+		v.Add("car_id", "is required")
+	} else if lead.CarID != "" && !domain.IsUUID(lead.CarID) {
+		v.Add("car_id", "unknown car")
+	}
+	validateInquiry(lead, v, now)
+	if err := v.OrNil(); err != nil {
+		leadMetrics.Add(leadRejectedInvalid, 1)
+		return false, err
+	}
+
+	if lead.CarID != "" {
+		car, err := s.Repo.GetCarByID(lead.CarID)
+		if errors.Is(err, domain.ErrCarNotFound) {
+			leadMetrics.Add(leadRejectedInvalid, 1)
+			v.Add("car_id", "unknown car")
+			return false, v
+		}
+		if err != nil {
+			return false, err
+		}
+		lead.CarModel = car.Make + " " + car.Model
+	}
+	lead.CarModel = truncate(lead.CarModel, maxLeadCarModel)
+
+	if err := s.admit(lead, src, now); err != nil {
+		return false, err
+	}
+
+	lead.Status = domain.LeadNew
+	merged, err = s.Repo.IntakeLead(lead, s.DedupWindow)
+	if err != nil {
+		return false, err
+	}
+	if merged {
+		leadMetrics.Add(leadMerged, 1)
+	} else {
+		leadMetrics.Add(leadAccepted, 1)
+	}
+	return merged, nil
+}
+
+// validateInquiry checks the details required by the lead's inquiry type and
+// drops the ones that belong to other types. Inquiries without a type are test
+// drives, which is all the lead form used to send.
+func validateInquiry(lead *domain.Lead, v *domain.ValidationError, now time.Time) {
+	if lead.InquiryType == "" {
+		lead.InquiryType = domain.InquiryTestDrive
+	}
+	in := lead.Details
+	out := domain.InquiryDetails{}
+
+	switch lead.InquiryType {
+	case domain.InquiryTestDrive:
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		date, err := time.ParseInLocation("2006-01-02", in.PreferredDate, now.Location())
+		switch {
+		case in.PreferredDate == "":
+			v.Add("details.preferred_date", "is required for a test drive")
+		case err != nil:
+			v.Add("details.preferred_date", "must be a date in YYYY-MM-DD format")
+		case date.Before(today):
+			v.Add("details.preferred_date", "must not be in the past")
+		case date.After(today.AddDate(0, 0, maxTestDriveDaysAhead)):
+			v.Add("details.preferred_date", fmt.Sprintf("must be within %d days", maxTestDriveDaysAhead))
+		}
+		if in.TimeSlot == "" {
+			v.Add("details.time_slot", "is required for a test drive")
+		}
+		out.PreferredDate, out.TimeSlot = in.PreferredDate, in.TimeSlot
+
+	case domain.InquiryTradeIn:
+		c := in.CurrentCar
+		if c == nil {
+			v.Add("details.current_car", "is required for a trade-in")
+			break
+		}
+		c.Make, c.Model = strings.TrimSpace(c.Make), strings.TrimSpace(c.Model)
+		if c.Make == "" {
+			v.Add("details.current_car.make", "is required")
+		}
+		if c.Model == "" {
+			v.Add("details.current_car.model", "is required")
+		}
+		if c.Year < minTradeInYear || c.Year > now.Year()+1 {
+			v.Add("details.current_car.year", fmt.Sprintf("must be between %d and %d", minTradeInYear, now.Year()+1))
+		}
+		if c.Mileage < 0 {
+			v.Add("details.current_car.mileage", "must not be negative")
+		}
+		out.CurrentCar = c
+
+	case domain.InquiryFinancing:
+		switch {
+		case in.DownPaymentKZT == nil:
+			v.Add("details.down_payment_kzt", "is required for financing")
+		case *in.DownPaymentKZT < 0:
+			v.Add("details.down_payment_kzt", "must not be negative")
+		}
+		if in.TermMonths != 0 && (in.TermMonths < minFinancingTerm || in.TermMonths > maxFinancingTerm) {
+			v.Add("details.term_months", fmt.Sprintf("must be between %d and %d", minFinancingTerm, maxFinancingTerm))
+		}
+		out.DownPaymentKZT, out.TermMonths = in.DownPaymentKZT, in.TermMonths
+
+	case domain.InquiryCallback:
+		out.TimeSlot = in.TimeSlot
+
+	case domain.InquiryPriceQuote:
+		// The car is all a quote needs.
+
+	default:
+		v.Add("inquiry_type", "must be one of: "+strings.Join(domain.InquiryTypes, ", "))
+	}
+
+	if out.TimeSlot != "" && !slices.Contains(domain.TimeSlots, out.TimeSlot) {
+		v.Add("details.time_slot", "must be one of: "+strings.Join(domain.TimeSlots, ", "))
+	}
+	lead.Details = out
+}
+
+// truncate cuts s to at most n runes.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// GetCarLeads returns the leads that asked about a car, newest first.
+func (s *LeadService) GetCarLeads(carID string) ([]domain.Lead, error) {
+	if _, err := s.Repo.GetCarByID(carID); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetLeadsByCar(carID)
+}
+
+// ListLeads returns every lead, newest first.
+func (s *LeadService) ListLeads() ([]domain.Lead, error) {
+	return s.Repo.GetAllLeads()
+}
+
+// GetLead returns a lead together with its notes and inquiries.
+func (s *LeadService) GetLead(id string) (*domain.Lead, error) {
+	lead, err := s.Repo.GetLeadByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if lead.Notes, err = s.Repo.GetLeadNotes(id); err != nil {
+		return nil, err
+	}
+	if lead.Touches, err = s.Repo.GetLeadTouches(id); err != nil {
+		return nil, err
+	}
+	return lead, nil
+}
+
+// UpdateLead moves a lead to another stage and/or (re)assigns it. Stage changes
+// must follow the pipeline (*domain.StatusTransitionError otherwise), and the
+// assignee must be an enabled admin or manager.
+func (s *LeadService) UpdateLead(id string, patch domain.LeadPatch) (*domain.Lead, error) {
+	v := &domain.ValidationError{}
+	if patch.Status == nil && patch.AssignedUserID == nil {
+		v.Add("body", "status or assigned_user_id must be provided")
+	}
+	if patch.Status != nil && !domain.IsLeadStatus(*patch.Status) {
+		v.Add("status", "unknown lead status")
+	}
+	if err := v.OrNil(); err != nil {
+		return nil, err
+	}
+
+	lead, err := s.Repo.GetLeadByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Status != nil {
+		if *patch.Status == lead.Status {
+			patch.Status = nil
+		} else if err := domain.ValidateLeadTransition(lead.Status, *patch.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	if patch.AssignedUserID != nil && *patch.AssignedUserID != "" {
+		user, err := s.Repo.GetUserByID(*patch.AssignedUserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidAssignee
+		}
+		if err != nil {
+			return nil, err
+		}
+		if user.Disabled || (user.Role != domain.RoleAdmin && user.Role != domain.RoleManager) {
+			return nil, domain.ErrInvalidAssignee
+		}
+	}
+
+	return s.Repo.UpdateLead(id, patch, lead.Status)
+}
+
+// AddNote attaches a salesperson's note to a lead.
+func (s *LeadService) AddNote(leadID, authorID, body string) (*domain.LeadNote, error) {
+	body = strings.TrimSpace(body)
+	v := &domain.ValidationError{}
+	checkText(v, "body", body, 5000, true)
+	if err := v.OrNil(); err != nil {
+		return nil, err
+	}
+
+	note := &domain.LeadNote{LeadID: leadID, AuthorID: authorID, Body: body}
+	if err := s.Repo.AddLeadNote(note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
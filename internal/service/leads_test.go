@@ -0,0 +1,223 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUpdateLeadTransitions(t *testing.T) {
+	cases := []struct {
+		from, to string
+		ok       bool
+	}{
+		{domain.LeadNew, domain.LeadContacted, true},
+		{domain.LeadNew, domain.LeadLost, true},
+		{domain.LeadContacted, domain.LeadTestDriveScheduled, true},
+		{domain.LeadTestDriveScheduled, domain.LeadNegotiating, true},
+		{domain.LeadNegotiating, domain.LeadWon, true},
+		{domain.LeadLost, domain.LeadContacted, true},
+		{domain.LeadNew, domain.LeadWon, false},
+		{domain.LeadNew, domain.LeadNegotiating, false},
+		{domain.LeadContacted, domain.LeadNew, false},
+		{domain.LeadWon, domain.LeadLost, false},
+		{domain.LeadWon, domain.LeadContacted, false},
+		{domain.LeadLost, domain.LeadWon, false},
+	}
+	for _, tc := range cases {
+		repo := newFakeRepo()
+		s := NewLeadService(repo)
+		lead := repo.addLead(tc.from)
+
+		to := tc.to
+		got, err := s.UpdateLead(lead.ID, domain.LeadPatch{Status: &to})
+		if tc.ok {
+			if err != nil {
+				t.Errorf("%s -> %s: %v", tc.from, tc.to, err)
+			} else if got.Status != tc.to {
+				t.Errorf("%s -> %s: status = %s", tc.from, tc.to, got.Status)
+			}
+			continue
+		}
+
+		var transitionErr *domain.StatusTransitionError
+		if !errors.As(err, &transitionErr) {
+			t.Errorf("%s -> %s = %v, want a StatusTransitionError", tc.from, tc.to, err)
+			continue
+		}
+		if transitionErr.From != tc.from || transitionErr.To != tc.to {
+			t.Errorf("%s -> %s: error reports %s -> %s", tc.from, tc.to, transitionErr.From, transitionErr.To)
+		}
+		if repo.leads[lead.ID].Status != tc.from {
+			t.Errorf("%s -> %s: rejected change was stored", tc.from, tc.to)
+		}
+	}
+}
+
+func TestUpdateLeadValidation(t *testing.T) {
+	repo := newFakeRepo()
+	s := NewLeadService(repo)
+	lead := repo.addLead(domain.LeadNew)
+
+	unknown := "qualified"
+	cases := map[string]domain.LeadPatch{
+		"empty patch":    {},
+		"unknown status": {Status: &unknown},
+	}
+	for name, patch := range cases {
+		if _, err := s.UpdateLead(lead.ID, patch); !errors.Is(err, domain.ErrValidation) {
+			t.Errorf("%s: UpdateLead = %v, want a validation error", name, err)
+		}
+	}
+
+	contacted := domain.LeadContacted
+	if _, err := s.UpdateLead(fakeUUID(999), domain.LeadPatch{Status: &contacted}); !errors.Is(err, domain.ErrLeadNotFound) {
+		t.Errorf("UpdateLead of a missing lead = %v, want ErrLeadNotFound", err)
+	}
+}
+
+func TestUpdateLeadConcurrentChange(t *testing.T) {
+	repo := newFakeRepo()
+	s := NewLeadService(repo)
+	lead := repo.addLead(domain.LeadContacted)
+
+	// Another salesperson closes the lead between our read and our write.
+	repo.beforeUpdateLead = func(l *domain.Lead) { l.Status = domain.LeadLost }
+
+	negotiating := domain.LeadNegotiating
+	if _, err := s.UpdateLead(lead.ID, domain.LeadPatch{Status: &negotiating}); !errors.Is(err, domain.ErrLeadChanged) {
+		t.Fatalf("UpdateLead after a concurrent change = %v, want ErrLeadChanged", err)
+	}
+	if got := repo.leads[lead.ID].Status; got != domain.LeadLost {
+		t.Errorf("status = %s, want the concurrent change (lost) to stand", got)
+	}
+}
+
+func TestUpdateLeadAssignee(t *testing.T) {
+	repo := newFakeRepo()
+	s := NewLeadService(repo)
+	lead := repo.addLead(domain.LeadNew)
+
+	manager := repo.addUser("dana", domain.RoleManager)
+	customer := repo.addUser("aida", domain.RoleUser)
+	disabled := repo.addUser("erlan", domain.RoleManager)
+	disabled.Disabled = true
+
+	for name, id := range map[string]string{"customer": customer.ID, "disabled manager": disabled.ID, "unknown user": fakeUUID(999)} {
+		if _, err := s.UpdateLead(lead.ID, domain.LeadPatch{AssignedUserID: &id}); !errors.Is(err, domain.ErrInvalidAssignee) {
+			t.Errorf("assigning a %s = %v, want ErrInvalidAssignee", name, err)
+		}
+	}
+
+	got, err := s.UpdateLead(lead.ID, domain.LeadPatch{AssignedUserID: &manager.ID})
+	if err != nil || got.AssignedUserID != manager.ID {
+		t.Fatalf("assigning a manager = %+v, %v", got, err)
+	}
+	unassign := ""
+	if got, err := s.UpdateLead(lead.ID, domain.LeadPatch{AssignedUserID: &unassign}); err != nil || got.AssignedUserID != "" {
+		t.Errorf("unassigning = %+v, %v", got, err)
+	}
+}
+
+func TestCreateLeadMergesRepeatInquiries(t *testing.T) {
+	const window = 24 * time.Hour
+
+	cases := []struct {
+		name string
+		// between changes the first lead, the clock or the second inquiry.
+		between func(repo *fakeRepo, first *domain.Lead, second *domain.Lead)
+		merged  bool
+	}{
+		{
+			name:    "same inquiry within the window",
+			between: func(repo *fakeRepo, first, second *domain.Lead) { repo.now = repo.now.Add(window - time.Minute) },
+			merged:  true,
+		},
+		{
+			name: "same number written differently",
+			between: func(repo *fakeRepo, first, second *domain.Lead) {
+				second.CustomerPhone = "8 (701) 123-45-67"
+			},
+			merged: true,
+		},
+		{
+			name:    "after the window",
+			between: func(repo *fakeRepo, first, second *domain.Lead) { repo.now = repo.now.Add(window + time.Minute) },
+		},
+		{
+			name:    "another phone",
+			between: func(repo *fakeRepo, first, second *domain.Lead) { second.CustomerPhone = "+7 701 765 43 21" },
+		},
+		{
+			name: "another car",
+			between: func(repo *fakeRepo, first, second *domain.Lead) {
+				second.CarID = repo.addCar("KNAGM4A70F5000001", "Kia", "K5").ID
+			},
+		},
+		{
+			name: "another inquiry type",
+			between: func(repo *fakeRepo, first, second *domain.Lead) {
+				second.InquiryType, second.Details = domain.InquiryPriceQuote, domain.InquiryDetails{}
+			},
+		},
+		{
+			name:    "closed lead",
+			between: func(repo *fakeRepo, first, second *domain.Lead) { repo.leads[first.ID].Status = domain.LeadWon },
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			s := NewLeadService(repo)
+			s.DedupWindow = window
+			car := repo.addCar("JTDBR32E720012345", "Toyota", "Camry")
+
+			first := testDriveInquiry(car.ID, "Aida", "morning")
+			if merged, err := s.CreateLead(first, LeadSource{}); err != nil || merged {
+				t.Fatalf("first CreateLead = %v, %v; want a new lead", merged, err)
+			}
+
+			second := testDriveInquiry(car.ID, "Aida Serikova", "evening")
+			tc.between(repo, first, second)
+			merged, err := s.CreateLead(second, LeadSource{})
+			if err != nil {
+				t.Fatalf("second CreateLead: %v", err)
+			}
+			if merged != tc.merged {
+				t.Fatalf("merged = %v, want %v", merged, tc.merged)
+			}
+
+			wantLeads := 2
+			if tc.merged {
+				wantLeads = 1
+				if second.ID != first.ID {
+					t.Errorf("merged into %s, want %s", second.ID, first.ID)
+				}
+				if second.Details.TimeSlot != "evening" || second.CustomerName != "Aida Serikova" {
+					t.Errorf("merged lead = %+v, want the latest details and name", second)
+				}
+				if repo.touches[first.ID] != 2 {
+					t.Errorf("%d touches on the lead, want 2", repo.touches[first.ID])
+				}
+			}
+			if len(repo.leads) != wantLeads {
+				t.Errorf("%d leads, want %d", len(repo.leads), wantLeads)
+			}
+		})
+	}
+}
+
+func testDriveInquiry(carID, name, slot string) *domain.Lead {
+	return &domain.Lead{
+		CarID:         carID,
+		CustomerName:  name,
+		CustomerPhone: "+7 701 123 45 67",
+		InquiryType:   domain.InquiryTestDrive,
+		Details: domain.InquiryDetails{
+			PreferredDate: time.Now().AddDate(0, 0, 3).Format("2006-01-02"),
+			TimeSlot:      slot,
+		},
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/pricing"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PricePreview is the price the worker would set for a car with today's stored rates.
+// While PriceLocked is set the worker leaves the current price alone.
+type PricePreview struct {
+	CarID           string  `json:"car_id"`
+	CurrentPriceKZT float64 `json:"current_price_kzt"`
+	PriceLocked     bool    `json:"price_locked"`
+	ExchangeRateID  string  `json:"exchange_rate_id"`
+	pricing.Breakdown
+}
+
+// ListPricingRules returns every configured pricing rule.
+func (s *AdminService) ListPricingRules() ([]domain.PricingRule, error) {
+	return s.Repo.GetPricingRules()
+}
+
+// CreatePricingRule validates and stores a new rule.
+func (s *AdminService) CreatePricingRule(rule *domain.PricingRule) error {
+	if err := validatePricingRule(rule); err != nil {
+		return err
+	}
+	return s.Repo.CreatePricingRule(rule)
+}
+
+// UpdatePricingRule validates and replaces an existing rule.
+func (s *AdminService) UpdatePricingRule(rule *domain.PricingRule) error {
+	if err := validatePricingRule(rule); err != nil {
+		return err
+	}
+	return s.Repo.UpdatePricingRule(rule)
+}
+
+// DeletePricingRule removes a rule; cars it matched fall back to a broader one.
+func (s *AdminService) DeletePricingRule(id string) error {
+	return s.Repo.DeletePricingRule(id)
+}
+
+// PreviewPrice shows how the next worker cycle would price a car, without changing it.
+func (s *AdminService) PreviewPrice(id string) (*PricePreview, error) {
+	car, err := s.Repo.GetCarByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.Repo.GetLatestRates()
+	if err != nil {
+		return nil, err
+	}
+	rate, ok := rates[car.PurchaseCurrency]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRateUnavailable, car.PurchaseCurrency)
+	}
+
+	engine, err := s.pricingEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PricePreview{
+		CarID:           car.ID,
+		CurrentPriceKZT: car.PriceKZT,
+		PriceLocked:     car.PriceLockActive(time.Now()),
+		ExchangeRateID:  rate.ID,
+		Breakdown:       engine.Price(car, rate.Rate),
+	}, nil
+}
+
+// pricingEngine loads the current rules from the database.
+func (s *AdminService) pricingEngine() (*pricing.Engine, error) {
+	rules, err := s.Repo.GetPricingRules()
+	if err != nil {
+		return nil, err
+	}
+	return pricing.NewEngine(rules), nil
+}
+
+func validatePricingRule(rule *domain.PricingRule) error {
+	v := &domain.ValidationError{}
+
+	rule.Make = strings.TrimSpace(rule.Make)
+	rule.Category = strings.ToLower(strings.TrimSpace(rule.Category))
+	checkText(v, "make", rule.Make, 50, false)
+	checkText(v, "category", rule.Category, 30, false)
+
+	if rule.MarkupPercent < 0 {
+		v.Add("markup_percent", "must not be negative")
+	}
+	if rule.CustomsFeeKZT < 0 {
+		v.Add("customs_fee_kzt", "must not be negative")
+	}
+	if rule.LogisticsFeeKZT < 0 {
+		v.Add("logistics_fee_kzt", "must not be negative")
+	}
+	if rule.VATPercent < 0 || rule.VATPercent > 100 {
+		v.Add("vat_percent", "must be between 0 and 100")
+	}
+	if rule.RoundingStepKZT <= 0 {
+		v.Add("rounding_step_kzt", "must be positive")
+	}
+	if rule.MinMarginKZT < 0 {
+		v.Add("min_margin_kzt", "must not be negative")
+	}
+
+	return v.OrNil()
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Claims is the JWT payload of an access token.
+type Claims struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// IssueTokens signs a short-lived access token and persists a new refresh token for the user.
+func (s *AuthService) IssueTokens(user *domain.User) (*domain.TokenPair, error) {
+	pair, refresh, err := s.newTokenPair(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.CreateRefreshToken(refresh); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new token pair, rotating the refresh token.
+// Presenting an already rotated token is treated as theft and logs the user out everywhere.
+func (s *AuthService) RefreshTokens(refreshToken string) (*domain.TokenPair, error) {
+	stored, err := s.Repo.GetRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		log.Printf("[Auth] Reuse of revoked refresh token detected for user %s, revoking all sessions", stored.UserID)
+		if err := s.Repo.RevokeAllUserSessions(stored.UserID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrTokenRevoked
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := s.Repo.GetUserByID(stored.UserID)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	if user.Disabled {
+		return nil, domain.ErrUserDisabled
+	}
+
+	pair, next, err := s.newTokenPair(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.RotateRefreshToken(stored.ID, next); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// VerifyAccessToken validates an access token and checks it against the revocation list.
+func (s *AuthService) VerifyAccessToken(tokenString string) (*domain.Principal, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return s.jwtKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidToken, err)
+	}
+	if claims.UserID == "" || claims.Role == "" || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	revoked, err := s.Repo.IsAccessTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, domain.ErrTokenRevoked
+	}
+
+	return &domain.Principal{
+		UserID:    claims.UserID,
+		Username:  claims.Username,
+		Role:      claims.Role,
+		TokenID:   claims.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// Logout revokes the caller's access token and, if provided, its refresh token.
+// A refresh token that is unknown or belongs to another user is rejected with
+// ErrInvalidToken before anything is revoked.
+func (s *AuthService) Logout(p *domain.Principal, refreshToken string) error {
+	if refreshToken != "" {
+		stored, err := s.Repo.GetRefreshTokenByHash(hashToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		if stored.UserID != p.UserID {
+			log.Printf("[Auth] User %s tried to revoke a refresh token of user %s", p.UserID, stored.UserID)
+			return domain.ErrInvalidToken
+		}
+	}
+
+	if err := s.Repo.RevokeAccessToken(p.TokenID, p.UserID, p.ExpiresAt); err != nil {
+		return err
+	}
+	if refreshToken == "" {
+		return nil
+	}
+	return s.Repo.RevokeRefreshToken(hashToken(refreshToken))
+}
+
+// LogoutAllSessions revokes every refresh and live access token of a user.
+func (s *AuthService) LogoutAllSessions(userID string) error {
+	if _, err := s.Repo.GetUserByID(userID); err != nil {
+		return err
+	}
+	return s.Repo.RevokeAllUserSessions(userID)
+}
+
+func (s *AuthService) newTokenPair(user *domain.User) (*domain.TokenPair, *domain.RefreshToken, error) {
+	now := time.Now()
+	accessExpires := now.Add(s.AccessTokenTTL)
+	refreshExpires := now.Add(s.RefreshTokenTTL)
+	jti := newRandomToken(16)
+
+	claims := Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(accessExpires),
+		},
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken := newRandomToken(32)
+	pair := &domain.TokenPair{
+		AccessToken:      accessToken,
+		ExpiresAt:        accessExpires,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpires,
+	}
+	stored := &domain.RefreshToken{
+		UserID:          user.ID,
+		TokenHash:       hashToken(refreshToken),
+		AccessTokenID:   jti,
+		AccessExpiresAt: accessExpires,
+		ExpiresAt:       refreshExpires,
+	}
+	return pair, stored, nil
+}
+
+func newRandomToken(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// durationFromEnv parses a Go duration (e.g. "15m") from the environment.
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("[WARNING] Invalid %s=%q, using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
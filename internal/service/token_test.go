@@ -0,0 +1,178 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestAuthService(repo *fakeRepo) *AuthService {
+	return &AuthService{
+		Repo:            repo,
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 24 * time.Hour,
+		jwtKey:          []byte("test secret"),
+	}
+}
+
+func TestRefreshTokens(t *testing.T) {
+	cases := []struct {
+		name string
+		// present returns the refresh token to exchange, after any setup.
+		present func(t *testing.T, s *AuthService, repo *fakeRepo, pair *domain.TokenPair) string
+		want    error
+	}{
+		{
+			name: "current token is rotated",
+			present: func(t *testing.T, s *AuthService, repo *fakeRepo, pair *domain.TokenPair) string {
+				return pair.RefreshToken
+			},
+		},
+		{
+			name: "unknown token",
+			present: func(t *testing.T, s *AuthService, repo *fakeRepo, pair *domain.TokenPair) string {
+				return "not-a-token"
+			},
+			want: domain.ErrInvalidToken,
+		},
+		{
+			name: "expired token",
+			present: func(t *testing.T, s *AuthService, repo *fakeRepo, pair *domain.TokenPair) string {
+				repo.refreshTokens[hashToken(pair.RefreshToken)].ExpiresAt = time.Now().Add(-time.Minute)
+				return pair.RefreshToken
+			},
+			want: domain.ErrInvalidToken,
+		},
+		{
+			name: "disabled user",
+			present: func(t *testing.T, s *AuthService, repo *fakeRepo, pair *domain.TokenPair) string {
+				for _, u := range repo.users {
+					u.Disabled = true
+				}
+				return pair.RefreshToken
+			},
+			want: domain.ErrUserDisabled,
+		},
+		{
+			name: "rotated token is reused",
+			present: func(t *testing.T, s *AuthService, repo *fakeRepo, pair *domain.TokenPair) string {
+				if _, err := s.RefreshTokens(pair.RefreshToken); err != nil {
+					t.Fatalf("first refresh: %v", err)
+				}
+				return pair.RefreshToken
+			},
+			want: domain.ErrTokenRevoked,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			s := newTestAuthService(repo)
+			pair, err := s.IssueTokens(repo.addUser("aida", domain.RoleUser))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			next, err := s.RefreshTokens(tc.present(t, s, repo, pair))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("RefreshTokens = %v, want %v", err, tc.want)
+			}
+			if tc.want != nil {
+				return
+			}
+			if next.RefreshToken == pair.RefreshToken {
+				t.Error("refresh token was not rotated")
+			}
+			if _, err := s.RefreshTokens(next.RefreshToken); err != nil {
+				t.Errorf("rotated token was not accepted: %v", err)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenReuseRevokesAllSessions(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestAuthService(repo)
+	aida, other := repo.addUser("aida", domain.RoleUser), repo.addUser("bota", domain.RoleUser)
+
+	laptop, err := s.IssueTokens(aida)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phone, err := s.IssueTokens(aida)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrelated, err := s.IssueTokens(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The laptop refreshes; a thief then replays the laptop's old token.
+	rotated, err := s.RefreshTokens(laptop.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RefreshTokens(laptop.RefreshToken); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Fatalf("reused token = %v, want ErrTokenRevoked", err)
+	}
+
+	for name, token := range map[string]string{"rotated": rotated.RefreshToken, "other session": phone.RefreshToken} {
+		if _, err := s.RefreshTokens(token); !errors.Is(err, domain.ErrTokenRevoked) {
+			t.Errorf("refresh with %s token after reuse = %v, want ErrTokenRevoked", name, err)
+		}
+	}
+	for name, token := range map[string]string{"rotated": rotated.AccessToken, "other session": phone.AccessToken} {
+		if _, err := s.VerifyAccessToken(token); !errors.Is(err, domain.ErrTokenRevoked) {
+			t.Errorf("%s access token after reuse = %v, want ErrTokenRevoked", name, err)
+		}
+	}
+
+	if _, err := s.VerifyAccessToken(unrelated.AccessToken); err != nil {
+		t.Errorf("another user's access token was revoked: %v", err)
+	}
+	if _, err := s.RefreshTokens(unrelated.RefreshToken); err != nil {
+		t.Errorf("another user's refresh token was revoked: %v", err)
+	}
+}
+
+func TestLogoutRejectsAnotherUsersRefreshToken(t *testing.T) {
+	repo := newFakeRepo()
+	s := newTestAuthService(repo)
+	aida, other := repo.addUser("aida", domain.RoleUser), repo.addUser("bota", domain.RoleUser)
+
+	mine, err := s.IssueTokens(aida)
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, err := s.IssueTokens(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal, err := s.VerifyAccessToken(mine.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Logout(principal, theirs.RefreshToken); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("Logout with another user's refresh token = %v, want ErrInvalidToken", err)
+	}
+	if _, err := s.RefreshTokens(theirs.RefreshToken); err != nil {
+		t.Errorf("another user's refresh token was revoked: %v", err)
+	}
+	if _, err := s.VerifyAccessToken(mine.AccessToken); err != nil {
+		t.Errorf("rejected logout revoked the caller's access token: %v", err)
+	}
+
+	if err := s.Logout(principal, mine.RefreshToken); err != nil {
+		t.Fatalf("Logout = %v", err)
+	}
+	if _, err := s.VerifyAccessToken(mine.AccessToken); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("access token after logout = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := s.RefreshTokens(mine.RefreshToken); !errors.Is(err, domain.ErrTokenRevoked) {
+		t.Errorf("refresh token after logout = %v, want ErrTokenRevoked", err)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 6
+
+// ListUsers returns all accounts for the admin user-management screen.
+func (s *AuthService) ListUsers() ([]domain.User, error) {
+	return s.Repo.ListUsers()
+}
+
+// CreateUser lets an admin create an account with any role.
+func (s *AuthService) CreateUser(username, password, role string) (*domain.User, error) {
+	if !domain.IsValidRole(role) {
+		return nil, domain.ErrInvalidRole
+	}
+	if len(password) < minPasswordLength {
+		return nil, domain.ErrWeakPassword
+	}
+
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		Username: username,
+		Password: string(hashedBytes),
+		Role:     role,
+	}
+	if err := s.Repo.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangeRole promotes or demotes a user and ends their sessions so the new role applies immediately.
+func (s *AuthService) ChangeRole(actorID, userID, role string) error {
+	if !domain.IsValidRole(role) {
+		return domain.ErrInvalidRole
+	}
+	if actorID == userID && role != domain.RoleAdmin {
+		return domain.ErrSelfLockout
+	}
+	if err := s.Repo.UpdateUserRole(userID, role); err != nil {
+		return err
+	}
+	return s.Repo.RevokeAllUserSessions(userID)
+}
+
+// SetDisabled blocks or unblocks an account. Disabling also revokes all of its sessions.
+func (s *AuthService) SetDisabled(actorID, userID string, disabled bool) error {
+	if actorID == userID && disabled {
+		return domain.ErrSelfLockout
+	}
+	if err := s.Repo.SetUserDisabled(userID, disabled); err != nil {
+		return err
+	}
+	if !disabled {
+		return nil
+	}
+	return s.Repo.RevokeAllUserSessions(userID)
+}
+
+// ResetPassword sets a new password for a user and logs them out everywhere.
+func (s *AuthService) ResetPassword(userID, password string) error {
+	if len(password) < minPasswordLength {
+		return domain.ErrWeakPassword
+	}
+
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return err
+	}
+	if err := s.Repo.UpdatePassword(userID, string(hashedBytes)); err != nil {
+		return err
+	}
+	return s.Repo.RevokeAllUserSessions(userID)
+}
+
+// SetEmail sets the address a user receives notifications at; "" removes it.
+func (s *AuthService) SetEmail(userID, email string) error {
+	email = strings.TrimSpace(email)
+	if email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email || len(email) > 255 {
+			v := &domain.ValidationError{}
+			v.Add("email", "must be a valid email address")
+			return v
+		}
+	}
+	return s.Repo.UpdateEmail(userID, email)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"Assignment3ADP/internal/domain"
+	"Assignment3ADP/internal/notify"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	defaultPriceDropAlertPercent = 5.0
+	notifyTimeout                = 10 * time.Second
+)
+
+// WatchCar subscribes a customer to price and availability alerts for a car.
+func (s *ClientService) WatchCar(userID, carID string) error {
+	return s.Repo.WatchCar(userID, carID)
+}
+
+// UnwatchCar stops alerts for a car.
+func (s *ClientService) UnwatchCar(userID, carID string) error {
+	return s.Repo.UnwatchCar(userID, carID)
+}
+
+// GetWatchlist returns the cars a customer is watching.
+func (s *ClientService) GetWatchlist(userID string) ([]domain.Car, error) {
+	return s.Repo.GetWatchlist(userID)
+}
+
+// notifyWatchers alerts watchers of cars whose price fell by more than
+// PriceDropAlertPercent since the last alert, or that became available.
+// A failed delivery leaves the watch untouched so it is retried next cycle; a
+// watcher without an email address is skipped and the watch moves on, since
+// retrying cannot succeed.
+func (s *AdminService) notifyWatchers(ctx context.Context) {
+	watches, err := s.Repo.GetWatches()
+	if err != nil {
+		log.Printf("[Worker Error] Failed to load watchlist: %v", err)
+		return
+	}
+
+	sent := 0
+	for _, w := range watches {
+		dropped := w.BaselinePriceKZT > 0 && w.PriceKZT > 0 &&
+			w.PriceKZT < w.BaselinePriceKZT*(1-s.PriceDropAlertPercent/100)
+		available := w.Status == domain.StatusAvailable && w.LastStatus != domain.StatusAvailable
+
+		if dropped || available {
+			nctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+			err := s.Notifier.Notify(nctx, watchNotification(w, dropped, available))
+			cancel()
+			switch {
+			case errors.Is(err, notify.ErrNoAddress):
+				log.Printf("[Worker] Skipping alert for %s about car %s: no email address", w.Username, w.CarID)
+			case err != nil:
+				log.Printf("[Worker Error] Failed to notify %s about car %s: %v", w.Username, w.CarID, err)
+				continue
+			default:
+				sent++
+			}
+		}
+
+		if !dropped && w.LastStatus == w.Status {
+			continue
+		}
+		if dropped {
+			w.BaselinePriceKZT = w.PriceKZT
+		}
+		w.LastStatus = w.Status
+		if err := s.Repo.UpdateWatch(w); err != nil {
+			log.Printf("[Worker Error] Failed to update watch of %s on car %s: %v", w.Username, w.CarID, err)
+		}
+	}
+
+	if sent > 0 {
+		log.Printf("[Worker] Sent %d watchlist notifications.", sent)
+	}
+}
+
+func watchNotification(w domain.Watch, dropped, available bool) domain.Notification {
+	car := w.CarMake + " " + w.CarModel
+	n := domain.Notification{Username: w.Username, Email: w.Email}
+
+	switch {
+	case dropped && available:
+		n.Subject = fmt.Sprintf("%s is available and cheaper", car)
+	case dropped:
+		n.Subject = fmt.Sprintf("Price drop on %s", car)
+	default:
+		n.Subject = fmt.Sprintf("%s is now available", car)
+	}
+
+	n.Body = fmt.Sprintf("Hello %s,\n\n", w.Username)
+	if dropped {
+		n.Body += fmt.Sprintf("The price of the %s you are watching fell from %.0f KZT to %.0f KZT.\n",
+			car, w.BaselinePriceKZT, w.PriceKZT)
+	}
+	if available {
+		n.Body += fmt.Sprintf("The %s you are watching is now available to book.\n", car)
+	}
+	n.Body += "\nYou receive this email because the car is on your watchlist."
+	return n
+}
@@ -1,71 +1,114 @@
 package service
 
 import (
+	"Assignment3ADP/internal/domain"
+	"context"
+	"fmt"
 	"log"
-	"math"
-	"math/rand"
 	"time"
 )
 
-// StartDailyCurrencyWorker runs indefinitely.
-func (s *AdminService) StartDailyCurrencyWorker() {
-	ticker := time.NewTicker(24 * time.Hour)
-	defer ticker.Stop()
+// rateFetchTimeout bounds a single call to the exchange-rate provider.
+const rateFetchTimeout = 30 * time.Second
 
-	log.Println("[Worker] Daily Currency Updater started. Waiting for next cycle...")
-	log.Println("[Worker] Running initial startup update...")
-	s.performDailyUpdate()
-
-	for {
-		<-ticker.C
-
-		log.Println("[Worker] Waking up to update currency rates...")
-		s.performDailyUpdate()
+// performDailyUpdate contains the core business logic for the worker. It fails
+// only when nothing could be priced; individual car failures are logged.
+func (s *AdminService) performDailyUpdate(ctx context.Context) (int, error) {
+	rates := s.refreshRates(ctx)
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(rates) == 0 {
+		return 0, domain.ErrRateUnavailable
 	}
-}
 
-// performDailyUpdate contains the core business logic for the worker
-func (s *AdminService) performDailyUpdate() {
-	rate, err := s.fetchExchangeRateUSD()
+	engine, err := s.pricingEngine()
 	if err != nil {
-		log.Printf("[Worker Error] Failed to fetch rates: %v", err)
-		return
+		return 0, fmt.Errorf("load pricing rules: %w", err)
 	}
-	log.Printf("[Worker] Current Exchange Rate: 1 USD = %.2f KZT", rate)
 
 	cars, err := s.Repo.GetAvailableCars()
 	if err != nil {
-		log.Printf("[Worker Error] DB fetch failed: %v", err)
-		return
+		return 0, fmt.Errorf("fetch cars: %w", err)
 	}
 
-	updatesCount := 0
+	now := time.Now()
+	var changes []domain.PriceChange
+	skipped, locked := 0, 0
 	for _, car := range cars {
-		rawPriceKZT := car.PriceUSD * rate
+		if car.PriceLockActive(now) {
+			locked++
+			continue
+		}
 
-		newPriceKZT := math.Round(rawPriceKZT/100000.0) * 100000.0
+		rate, ok := rates[car.PurchaseCurrency]
+		if !ok {
+			skipped++
+			continue
+		}
+
+		newPriceKZT := engine.Price(&car, rate.Rate).PriceKZT
 
 		if newPriceKZT != car.PriceKZT {
-			err := s.Repo.UpdatePrice(car.ID, newPriceKZT)
-			if err != nil {
-				log.Printf("[Worker Error] Failed to update CarID %s: %v", car.ID, err)
-			} else {
-				updatesCount++
-			}
+			changes = append(changes, domain.PriceChange{
+				CarID:          car.ID,
+				NewPriceKZT:    newPriceKZT,
+				ExchangeRateID: rate.ID,
+				Reason:         "daily exchange rate update",
+			})
 		}
 	}
 
-	log.Printf("[Worker] Cycle Complete. Updated prices for %d cars.", updatesCount)
-}
+	// All or nothing: a failed batch leaves every car on its previous price.
+	applied, err := s.Repo.UpdatePrices(changes)
+	if err != nil {
+		return 0, fmt.Errorf("apply %d price changes: %w", len(changes), err)
+	}
+	for _, c := range applied {
+		log.Printf("[Worker] CarID %s: %.0f -> %.0f KZT", c.CarID, c.OldPriceKZT, c.NewPriceKZT)
+	}
+	// Cars locked by an override between the read above and the batch are left out.
+	locked += len(changes) - len(applied)
 
-// fetchExchangeRateUSD simulates calling the National Bank API
-func (s *AdminService) fetchExchangeRateUSD() (float64, error) {
+	if locked > 0 {
+		log.Printf("[Worker] Left %d cars with a manually locked price untouched.", locked)
+	}
+	if skipped > 0 {
+		log.Printf("[Worker] Skipped %d cars with no fresh rate for their purchase currency.", skipped)
+	}
+	// Alerts are best-effort: a delivery failure must not roll back or retry the repricing.
+	s.notifyWatchers(ctx)
+
+	log.Printf("[Worker] Cycle Complete. Updated prices for %d cars.", len(applied))
+	return len(applied), nil
+}
 
-	time.Sleep(500 * time.Millisecond)
+// refreshRates fetches today's rates for every supported currency in one request
+// and stores them. A currency that is missing or fails to save is logged and left
+// out, so only its cars keep their old price.
+func (s *AdminService) refreshRates(ctx context.Context) map[string]*domain.ExchangeRate {
+	ctx, cancel := context.WithTimeout(ctx, rateFetchTimeout)
+	defer cancel()
 
-	mini := 520.0
-	maxi := 530.0
-	rate := mini + rand.Float64()*(maxi-mini)
+	fetched, err := s.Rates.FetchRates(ctx, domain.SupportedCurrencies)
+	if err != nil {
+		log.Printf("[Worker Error] Failed to fetch exchange rates: %v", err)
+		return map[string]*domain.ExchangeRate{}
+	}
 
-	return rate, nil
+	rates := map[string]*domain.ExchangeRate{}
+	for _, currency := range domain.SupportedCurrencies {
+		rate, ok := fetched[currency]
+		if !ok {
+			log.Printf("[Worker Error] Failed to fetch %s rate: %v", currency, domain.ErrRateUnavailable)
+			continue
+		}
+		if err := s.Repo.SaveExchangeRate(rate); err != nil {
+			log.Printf("[Worker Error] Failed to store %s rate: %v", currency, err)
+			continue
+		}
+		log.Printf("[Worker] Current Exchange Rate: 1 %s = %.4f KZT (source: %s)", currency, rate.Rate, rate.Source)
+		rates[currency] = rate
+	}
+	return rates
 }
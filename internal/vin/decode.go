@@ -0,0 +1,173 @@
+package vin
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+//go:embed wmi.json
+var embeddedTable []byte
+
+// alphabet is the ISO 3779 ordering used for the country code ranges.
+const alphabet = "ABCDEFGHJKLMNPRSTUVWXYZ1234567890"
+
+// yearCodes are the model-year characters (position 10), starting at 1980 and repeating every 30 years.
+const yearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// CountryRange assigns the two-character prefixes From..To (inclusive) to a country.
+type CountryRange struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Country string `json:"country"`
+}
+
+// Manufacturer is the lookup entry for a World Manufacturer Identifier.
+type Manufacturer struct {
+	Manufacturer string `json:"manufacturer"`
+	Make         string `json:"make"`
+}
+
+// Table is the lookup data used by a Decoder.
+type Table struct {
+	Countries     []CountryRange          `json:"countries"`
+	Manufacturers map[string]Manufacturer `json:"manufacturers"`
+}
+
+// Decoded describes what can be read from a VIN without an external service.
+type Decoded struct {
+	VIN             string `json:"vin"`
+	WMI             string `json:"wmi"`
+	VDS             string `json:"vds"`
+	VIS             string `json:"vis"`
+	Region          string `json:"region"`
+	Country         string `json:"country,omitempty"`
+	Manufacturer    string `json:"manufacturer,omitempty"`
+	Make            string `json:"make,omitempty"`
+	ModelYear       int    `json:"model_year,omitempty"`
+	PlantCode       string `json:"plant_code"`
+	SerialNumber    string `json:"serial_number"`
+	CheckDigitValid bool   `json:"check_digit_valid"`
+}
+
+// Decoder splits VINs into their WMI/VDS/VIS sections and resolves them against a Table.
+type Decoder struct {
+	table Table
+	now   func() time.Time
+}
+
+func NewDecoder(table Table) *Decoder {
+	return &Decoder{table: table, now: time.Now}
+}
+
+// DefaultTable returns the lookup table compiled into the binary.
+func DefaultTable() Table {
+	var t Table
+	if err := json.Unmarshal(embeddedTable, &t); err != nil {
+		panic("vin: embedded wmi.json is invalid: " + err.Error())
+	}
+	return t
+}
+
+// LoadTable reads a replacement lookup table in the same format as the embedded wmi.json.
+func LoadTable(path string) (Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Table{}, err
+	}
+	var t Table
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Table{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Decode reads a VIN. Unlike Validate it accepts a wrong check digit, which many
+// non-North-American manufacturers do not compute, and reports it instead.
+func (d *Decoder) Decode(raw string) (*Decoded, error) {
+	v := Normalize(raw)
+	err := Validate(v)
+	if err != nil && err != ErrCheckDigit {
+		return nil, err
+	}
+
+	res := &Decoded{
+		VIN:             v,
+		WMI:             v[0:3],
+		VDS:             v[3:9],
+		VIS:             v[9:17],
+		Region:          region(v[0]),
+		Country:         d.country(v[0:2]),
+		PlantCode:       v[10:11],
+		SerialNumber:    v[11:17],
+		CheckDigitValid: err == nil,
+	}
+	if m, ok := d.table.Manufacturers[res.WMI]; ok {
+		res.Manufacturer = m.Manufacturer
+		res.Make = m.Make
+	}
+	res.ModelYear = d.modelYear(v)
+	return res, nil
+}
+
+func (d *Decoder) country(prefix string) string {
+	pos := rank(prefix)
+	if pos < 0 {
+		return ""
+	}
+	for _, c := range d.table.Countries {
+		if len(c.From) != 2 || len(c.To) != 2 || c.From[0] != prefix[0] {
+			continue
+		}
+		if rank(c.From) <= pos && pos <= rank(c.To) {
+			return c.Country
+		}
+	}
+	return ""
+}
+
+// rank orders a two-character prefix by its second character within the ISO 3779 alphabet.
+func rank(prefix string) int {
+	return strings.IndexByte(alphabet, prefix[1])
+}
+
+// modelYear resolves position 10. The code repeats every 30 years; for North
+// American VINs a letter in position 7 selects the 2010+ cycle, otherwise the
+// most recent year not in the future is chosen. A year past next year's models
+// cannot be right, so the earlier cycle is used instead.
+func (d *Decoder) modelYear(v string) int {
+	idx := strings.IndexByte(yearCodes, v[9])
+	if idx < 0 {
+		return 0
+	}
+	early, late := 1980+idx, 2010+idx
+	if late > d.now().Year()+1 {
+		return early
+	}
+
+	if strings.ContainsRune("12345", rune(v[0])) && !(v[6] >= 'A' && v[6] <= 'Z') {
+		return early
+	}
+	return late
+}
+
+func region(first byte) string {
+	switch {
+	case first >= 'A' && first <= 'H':
+		return "Africa"
+	case first >= 'J' && first <= 'R':
+		return "Asia"
+	case first >= 'S' && first <= 'Z':
+		return "Europe"
+	case first >= '1' && first <= '5':
+		return "North America"
+	case first == '6' || first == '7':
+		return "Oceania"
+	case first == '8' || first == '9':
+		return "South America"
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package vin
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	d := NewDecoder(DefaultTable())
+	d.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	got, err := d.Decode("1hgcm82633a004352")
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	want := Decoded{
+		VIN:             "1HGCM82633A004352",
+		WMI:             "1HG",
+		VDS:             "CM8263",
+		VIS:             "3A004352",
+		Region:          "North America",
+		Country:         "United States",
+		Manufacturer:    "Honda of America",
+		Make:            "Honda",
+		ModelYear:       2003,
+		PlantCode:       "A",
+		SerialNumber:    "004352",
+		CheckDigitValid: true,
+	}
+	if *got != want {
+		t.Errorf("Decode =\n%+v\nwant\n%+v", *got, want)
+	}
+}
+
+func TestDecodeWrongCheckDigit(t *testing.T) {
+	// European manufacturers often leave the check digit unset; Decode still reads the VIN.
+	got, err := NewDecoder(DefaultTable()).Decode("WVWZZZ1JZ3W386752")
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.CheckDigitValid {
+		t.Error("CheckDigitValid = true, want false")
+	}
+	if got.Make != "Volkswagen" || got.Country != "Germany" || got.Region != "Europe" {
+		t.Errorf("Decode = %+v", got)
+	}
+
+	if _, err := NewDecoder(DefaultTable()).Decode("WVWZZZ1JZ3W38675"); !errors.Is(err, ErrLength) {
+		t.Errorf("Decode of short VIN = %v, want ErrLength", err)
+	}
+}
+
+func TestModelYear(t *testing.T) {
+	cases := []struct {
+		vin  string
+		now  int
+		want int
+	}{
+		{"1HGCM82633A004352", 2026, 2003}, // North America, digit in position 7: 1980 cycle
+		{"1HGCM8A63AA004352", 2026, 2010}, // North America, letter in position 7: 2010 cycle
+		{"1HGCM8A633A004352", 2026, 2003}, // ... unless that year is still in the future
+		{"1HGCM8A63TA004352", 2026, 2026},
+		{"1HGCM8A63VA004352", 2026, 2027}, // next year's models are already on sale
+		{"WVWZZZ1JZAW386752", 2026, 2010}, // elsewhere: the latest year not in the future
+		{"WVWZZZ1JZAW386752", 2005, 1980},
+		{"WVWZZZ1JZ3W386752", 2026, 2003},
+		{"WVWZZZ1JZUW386752", 2026, 0}, // U is not a year code
+	}
+	for _, tc := range cases {
+		d := NewDecoder(Table{})
+		d.now = func() time.Time { return time.Date(tc.now, 6, 1, 0, 0, 0, 0, time.UTC) }
+		if got := d.modelYear(tc.vin); got != tc.want {
+			t.Errorf("modelYear(%q) in %d = %d, want %d", tc.vin, tc.now, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Package vin validates Vehicle Identification Numbers (ISO 3779).
+package vin
+
+import (
+	"errors"
+	"strings"
+)
+
+// Length is the number of characters in a modern (post-1981) VIN.
+const Length = 17
+
+var (
+	ErrLength     = errors.New("VIN must be exactly 17 characters")
+	ErrCharacter  = errors.New("VIN may contain only digits and letters except I, O and Q")
+	ErrCheckDigit = errors.New("VIN check digit (9th character) does not match")
+)
+
+// transliteration maps each allowed letter to its numeric value for the check digit.
+var transliteration = map[rune]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+// weights are the positional multipliers; position 9 (the check digit itself) weighs 0.
+var weights = [Length]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// Normalize upper-cases a VIN and strips surrounding whitespace.
+func Normalize(v string) string {
+	return strings.ToUpper(strings.TrimSpace(v))
+}
+
+// Validate checks length, alphabet and the check digit of a normalized VIN.
+func Validate(v string) error {
+	if len(v) != Length {
+		return ErrLength
+	}
+
+	sum := 0
+	for i, ch := range v {
+		value, ok := charValue(ch)
+		if !ok {
+			return ErrCharacter
+		}
+		sum += value * weights[i]
+	}
+
+	if CheckDigit(sum) != v[8] {
+		return ErrCheckDigit
+	}
+	return nil
+}
+
+// CheckDigitRequired reports whether a VIN must carry a valid check digit. Only
+// North American VINs (first character 1-5) are required to; most European and
+// Asian manufacturers use position 9 for other data.
+func CheckDigitRequired(v string) bool {
+	return v != "" && v[0] >= '1' && v[0] <= '5'
+}
+
+// CheckDigit converts a weighted sum into the expected 9th character.
+func CheckDigit(weightedSum int) byte {
+	remainder := weightedSum % 11
+	if remainder == 10 {
+		return 'X'
+	}
+	return byte('0' + remainder)
+}
+
+func charValue(ch rune) (int, bool) {
+	if ch >= '0' && ch <= '9' {
+		return int(ch - '0'), true
+	}
+	value, ok := transliteration[ch]
+	return value, ok
+}
@@ -0,0 +1,50 @@
+package vin
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		vin  string
+		want error
+	}{
+		{"1HGCM82633A004352", nil},
+		{"11111111111111111", nil},
+		{"1M8GDM9AXKP042788", nil}, // check digit X
+		{"1HGCM82633A00435", ErrLength},
+		{"1HGCM82633A0043521", ErrLength},
+		{"1HGCM82633A00435I", ErrCharacter},
+		{"1HGCM82O33A004352", ErrCharacter},
+		{"1HGCM82643A004352", ErrCheckDigit},
+	}
+	for _, tc := range cases {
+		if err := Validate(tc.vin); !errors.Is(err, tc.want) {
+			t.Errorf("Validate(%q) = %v, want %v", tc.vin, err, tc.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("  1hgcm82633a004352\n"); got != "1HGCM82633A004352" {
+		t.Errorf("Normalize = %q", got)
+	}
+}
+
+func TestCheckDigitRequired(t *testing.T) {
+	cases := map[string]bool{
+		"1HGCM82633A004352": true,  // United States
+		"2T1BURHE0JC014488": true,  // Canada
+		"3VWFE21C04M000001": true,  // Mexico
+		"WVWZZZ1JZ3W386752": false, // Germany
+		"KMHDN41BP6U366989": false, // Korea
+		"LSGPC52U55F000001": false, // China
+		"":                  false,
+	}
+	for v, want := range cases {
+		if got := CheckDigitRequired(v); got != want {
+			t.Errorf("CheckDigitRequired(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
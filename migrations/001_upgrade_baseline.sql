@@ -0,0 +1,141 @@
+-- Brings a database created from the original schema.sql up to date with the
+-- auth, inventory, pricing, watchlist and lead pipeline changes, so the later
+-- numbered scripts can run after it. Existing rows are kept. Safe to run more
+-- than once.
+
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Users: account disabling and alert email
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Cars: inventory attributes, purchase currency, price locks, status tracking and optimistic locking
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS year INTEGER;
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS mileage INTEGER NOT NULL DEFAULT 0 CHECK (mileage >= 0);
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS color VARCHAR(30);
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS category VARCHAR(30);
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS purchase_currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS purchase_price DECIMAL(16, 2);
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS price_locked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS price_locked_until TIMESTAMPTZ;
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS status_changed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP;
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS sold_at TIMESTAMP;
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(make, '') || ' ' || COALESCE(model, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(vin, '')), 'B') ||
+    setweight(to_tsvector('simple', COALESCE(description, '')), 'C')
+) STORED;
+
+-- Leads: pipeline stages and assignment
+UPDATE leads SET status = 'new'
+WHERE status IS NULL OR status NOT IN ('new', 'contacted', 'test_drive_scheduled', 'negotiating', 'won', 'lost');
+ALTER TABLE leads ALTER COLUMN status TYPE VARCHAR(30);
+ALTER TABLE leads ALTER COLUMN status SET NOT NULL;
+ALTER TABLE leads DROP CONSTRAINT IF EXISTS leads_status_check;
+ALTER TABLE leads ADD CONSTRAINT leads_status_check
+    CHECK (status IN ('new', 'contacted', 'test_drive_scheduled', 'negotiating', 'won', 'lost'));
+ALTER TABLE leads ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+UPDATE leads SET updated_at = created_at WHERE updated_at IS NULL;
+ALTER TABLE leads ALTER COLUMN updated_at SET DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS lead_notes (
+    id BIGSERIAL PRIMARY KEY,
+    lead_id UUID NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS car_status_events (
+    id BIGSERIAL PRIMARY KEY,
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    occurred_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id BIGSERIAL PRIMARY KEY,
+    currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL DEFAULT 'KZT',
+    rate NUMERIC(18, 6) NOT NULL CHECK (rate > 0),
+    source VARCHAR(100) NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id BIGSERIAL PRIMARY KEY,
+    make VARCHAR(50),
+    category VARCHAR(30),
+    markup_percent NUMERIC(6, 2) NOT NULL DEFAULT 0 CHECK (markup_percent >= 0),
+    customs_fee_kzt NUMERIC(15, 2) NOT NULL DEFAULT 0 CHECK (customs_fee_kzt >= 0),
+    logistics_fee_kzt NUMERIC(15, 2) NOT NULL DEFAULT 0 CHECK (logistics_fee_kzt >= 0),
+    vat_percent NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (vat_percent >= 0 AND vat_percent <= 100),
+    rounding_step_kzt NUMERIC(15, 2) NOT NULL DEFAULT 100000 CHECK (rounding_step_kzt > 0),
+    min_margin_kzt NUMERIC(15, 2) NOT NULL DEFAULT 0 CHECK (min_margin_kzt >= 0),
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS price_changes (
+    id BIGSERIAL PRIMARY KEY,
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    old_price_kzt DECIMAL(15, 2),
+    new_price_kzt DECIMAL(15, 2) NOT NULL,
+    exchange_rate_id BIGINT REFERENCES exchange_rates(id) ON DELETE SET NULL,
+    reason TEXT,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS watchlist (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    baseline_price_kzt DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    last_status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, car_id)
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    access_token_id VARCHAR(64) NOT NULL,
+    access_expires_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    replaced_by UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_cars_search ON cars USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_cars_make_model_trgm ON cars USING GIN ((make || ' ' || model) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_leads_assigned ON leads(assigned_user_id, status);
+CREATE INDEX IF NOT EXISTS idx_lead_notes_lead ON lead_notes(lead_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_car_status_events_car ON car_status_events(car_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_currency ON exchange_rates(currency, fetched_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pricing_rules_scope ON pricing_rules(LOWER(COALESCE(make, '')), COALESCE(category, ''));
+CREATE INDEX IF NOT EXISTS idx_watchlist_car ON watchlist(car_id);
+CREATE INDEX IF NOT EXISTS idx_price_changes_car ON price_changes(car_id, changed_at DESC);
+
+-- Catch-all rule matching the original flat conversion
+INSERT INTO pricing_rules (rounding_step_kzt)
+SELECT 100000 WHERE NOT EXISTS (SELECT 1 FROM pricing_rules WHERE make IS NULL AND category IS NULL);
+
+COMMIT;
@@ -0,0 +1,42 @@
+-- Links existing leads to the car they asked about.
+-- Leads used to store only a free-text car_model ("Make Model" from the car details
+-- form). This adds leads.car_id and fills it where the text identifies exactly one
+-- car; ambiguous or unknown models stay NULL and keep their car_model text.
+-- Safe to run more than once.
+
+BEGIN;
+
+ALTER TABLE leads ADD COLUMN IF NOT EXISTS car_id UUID REFERENCES cars(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_leads_car ON leads(car_id);
+
+-- Pass 1: "Make Model", as sent by the old car details form
+WITH matches AS (
+    SELECT l.id AS lead_id, MIN(c.id::text)::uuid AS car_id
+    FROM leads l
+    JOIN cars c ON LOWER(TRIM(l.car_model)) = LOWER(c.make || ' ' || c.model)
+    WHERE l.car_id IS NULL
+    GROUP BY l.id
+    HAVING COUNT(*) = 1
+)
+UPDATE leads l SET car_id = m.car_id FROM matches m WHERE l.id = m.lead_id;
+
+-- Pass 2: the model name alone
+WITH matches AS (
+    SELECT l.id AS lead_id, MIN(c.id::text)::uuid AS car_id
+    FROM leads l
+    JOIN cars c ON LOWER(TRIM(l.car_model)) = LOWER(c.model)
+    WHERE l.car_id IS NULL
+    GROUP BY l.id
+    HAVING COUNT(*) = 1
+)
+UPDATE leads l SET car_id = m.car_id FROM matches m WHERE l.id = m.lead_id;
+
+-- Pass 3: a VIN typed into the model field
+UPDATE leads l SET car_id = c.id
+FROM cars c
+WHERE l.car_id IS NULL AND UPPER(TRIM(l.car_model)) = c.vin;
+
+COMMIT;
+
+-- Leads left for manual review
+SELECT id, car_model, customer_name, created_at FROM leads WHERE car_id IS NULL ORDER BY created_at;
@@ -0,0 +1,29 @@
+-- Adds duplicate detection for lead intake.
+-- leads.last_inquiry_at marks the customer's latest inquiry, and lead_touches keeps
+-- every inquiry merged into a lead. Existing leads get one touch each.
+-- Phones stored before this change are not normalized to E.164, so they will not
+-- be matched against new inquiries. Safe to run more than once.
+
+BEGIN;
+
+ALTER TABLE leads ADD COLUMN IF NOT EXISTS last_inquiry_at TIMESTAMP;
+UPDATE leads SET last_inquiry_at = created_at WHERE last_inquiry_at IS NULL;
+ALTER TABLE leads ALTER COLUMN last_inquiry_at SET DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS lead_touches (
+    id BIGSERIAL PRIMARY KEY,
+    lead_id UUID NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+    car_id UUID REFERENCES cars(id) ON DELETE SET NULL,
+    car_model VARCHAR(50),
+    customer_name VARCHAR(100),
+    inquiry_type VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_lead_touches_lead ON lead_touches(lead_id, created_at);
+
+INSERT INTO lead_touches (lead_id, car_id, car_model, customer_name, inquiry_type, created_at)
+SELECT l.id, l.car_id, l.car_model, l.customer_name, l.inquiry_type, l.created_at
+FROM leads l
+WHERE NOT EXISTS (SELECT 1 FROM lead_touches t WHERE t.lead_id = l.id);
+
+COMMIT;
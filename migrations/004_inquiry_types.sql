@@ -0,0 +1,15 @@
+-- Adds typed inquiries to leads.
+-- Each inquiry type keeps its own fields (preferred date, trade-in car, down
+-- payment, ...) in a details JSONB column. Every existing lead was a test drive
+-- submitted without details. Safe to run more than once.
+
+BEGIN;
+
+ALTER TABLE leads ADD COLUMN IF NOT EXISTS details JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE lead_touches ADD COLUMN IF NOT EXISTS details JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE leads DROP CONSTRAINT IF EXISTS leads_inquiry_type_check;
+ALTER TABLE leads ADD CONSTRAINT leads_inquiry_type_check
+    CHECK (inquiry_type IN ('test_drive', 'price_quote', 'trade_in', 'financing', 'callback'));
+
+COMMIT;
@@ -1,16 +1,28 @@
 -- 1. Clean up existing tables
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS price_changes;
+DROP TABLE IF EXISTS pricing_rules;
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS watchlist;
+DROP TABLE IF EXISTS car_status_events;
+DROP TABLE IF EXISTS lead_touches;
+DROP TABLE IF EXISTS lead_notes;
 DROP TABLE IF EXISTS leads;
 DROP TABLE IF EXISTS cars;
 DROP TABLE IF EXISTS users;
 
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
 -- 2. Create 'users' table
 CREATE TABLE users (
                        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
                        username VARCHAR(100) UNIQUE NOT NULL,
+                       email VARCHAR(255),
                        password_hash VARCHAR(255) NOT NULL,
                        role VARCHAR(50) NOT NULL CHECK (role IN ('admin', 'manager', 'user')),
+                       disabled BOOLEAN NOT NULL DEFAULT FALSE,
                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -20,39 +32,175 @@ CREATE TABLE cars (
                       vin VARCHAR(50) UNIQUE NOT NULL,
                       make VARCHAR(50) NOT NULL,
                       model VARCHAR(50) NOT NULL,
+                      year INTEGER,
+                      mileage INTEGER NOT NULL DEFAULT 0 CHECK (mileage >= 0),
+                      color VARCHAR(30),
+                      description TEXT,
+                      category VARCHAR(30),
+                      purchase_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+                      purchase_price DECIMAL(16, 2),
                       price_usd DECIMAL(12, 2) NOT NULL,
                       price_kzt DECIMAL(15, 2) DEFAULT 0,
+                      price_locked BOOLEAN NOT NULL DEFAULT FALSE,
+                      price_locked_until TIMESTAMPTZ,
                       status VARCHAR(20) NOT NULL DEFAULT 'transit'
                           CHECK (status IN ('available', 'transit', 'reserved', 'sold')),
+                      status_changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                      status_changed_at TIMESTAMP,
+                      sold_at TIMESTAMP,
 
     -- NEW: Image URL Column
                       image_url VARCHAR(255),
 
                       location VARCHAR(100),
                       user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+                      version INTEGER NOT NULL DEFAULT 1,
                       created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-                      updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                      updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- Full-text search document: make and model weigh most, then VIN, then description
+                      search_vector TSVECTOR GENERATED ALWAYS AS (
+                          setweight(to_tsvector('simple', COALESCE(make, '') || ' ' || COALESCE(model, '')), 'A') ||
+                          setweight(to_tsvector('simple', COALESCE(vin, '')), 'B') ||
+                          setweight(to_tsvector('simple', COALESCE(description, '')), 'C')
+                      ) STORED
 );
 
--- 4. Create 'leads' table
+-- 4. Create 'leads', 'lead_notes' and 'lead_touches' tables
 CREATE TABLE leads (
                        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                       car_id UUID REFERENCES cars(id) ON DELETE SET NULL,
                        car_model varchar(50),
                        assigned_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
                        customer_name VARCHAR(100),
                        customer_phone VARCHAR(20) NOT NULL,
+                       inquiry_type VARCHAR(50) NOT NULL
+                           CHECK (inquiry_type IN ('test_drive', 'price_quote', 'trade_in', 'financing', 'callback')),
+                       details JSONB NOT NULL DEFAULT '{}',
+                       status VARCHAR(30) NOT NULL DEFAULT 'new'
+                           CHECK (status IN ('new', 'contacted', 'test_drive_scheduled', 'negotiating', 'won', 'lost')),
+                       last_inquiry_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                       created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                       updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE lead_notes (
+                       id BIGSERIAL PRIMARY KEY,
+                       lead_id UUID NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+                       author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+                       body TEXT NOT NULL,
+                       created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every inquiry a customer submits; repeats from the same phone are merged into one lead
+CREATE TABLE lead_touches (
+                       id BIGSERIAL PRIMARY KEY,
+                       lead_id UUID NOT NULL REFERENCES leads(id) ON DELETE CASCADE,
+                       car_id UUID REFERENCES cars(id) ON DELETE SET NULL,
+                       car_model VARCHAR(50),
+                       customer_name VARCHAR(100),
                        inquiry_type VARCHAR(50) NOT NULL,
-                       status VARCHAR(20) DEFAULT 'new',
+                       details JSONB NOT NULL DEFAULT '{}',
                        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- 5. Create car status history table
+CREATE TABLE car_status_events (
+                       id BIGSERIAL PRIMARY KEY,
+                       car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+                       from_status VARCHAR(20) NOT NULL,
+                       to_status VARCHAR(20) NOT NULL,
+                       actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+                       reason TEXT,
+                       occurred_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 6. Create exchange rate, pricing rule and price history tables
+CREATE TABLE exchange_rates (
+                       id BIGSERIAL PRIMARY KEY,
+                       currency VARCHAR(3) NOT NULL,
+                       quote_currency VARCHAR(3) NOT NULL DEFAULT 'KZT',
+                       rate NUMERIC(18, 6) NOT NULL CHECK (rate > 0),
+                       source VARCHAR(100) NOT NULL,
+                       fetched_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE pricing_rules (
+                       id BIGSERIAL PRIMARY KEY,
+                       make VARCHAR(50),
+                       category VARCHAR(30),
+                       markup_percent NUMERIC(6, 2) NOT NULL DEFAULT 0 CHECK (markup_percent >= 0),
+                       customs_fee_kzt NUMERIC(15, 2) NOT NULL DEFAULT 0 CHECK (customs_fee_kzt >= 0),
+                       logistics_fee_kzt NUMERIC(15, 2) NOT NULL DEFAULT 0 CHECK (logistics_fee_kzt >= 0),
+                       vat_percent NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (vat_percent >= 0 AND vat_percent <= 100),
+                       rounding_step_kzt NUMERIC(15, 2) NOT NULL DEFAULT 100000 CHECK (rounding_step_kzt > 0),
+                       min_margin_kzt NUMERIC(15, 2) NOT NULL DEFAULT 0 CHECK (min_margin_kzt >= 0),
+                       updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE price_changes (
+                       id BIGSERIAL PRIMARY KEY,
+                       car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+                       old_price_kzt DECIMAL(15, 2),
+                       new_price_kzt DECIMAL(15, 2) NOT NULL,
+                       exchange_rate_id BIGINT REFERENCES exchange_rates(id) ON DELETE SET NULL,
+                       reason TEXT,
+                       changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                       changed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 7. Create watchlist table
+CREATE TABLE watchlist (
+                       user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                       car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+                       baseline_price_kzt DECIMAL(15, 2) NOT NULL DEFAULT 0,
+                       last_status VARCHAR(20) NOT NULL,
+                       created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+                       PRIMARY KEY (user_id, car_id)
+);
+
+-- 8. Create auth session tables
+CREATE TABLE refresh_tokens (
+                       id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                       user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                       token_hash VARCHAR(64) UNIQUE NOT NULL,
+                       access_token_id VARCHAR(64) NOT NULL,
+                       access_expires_at TIMESTAMPTZ NOT NULL,
+                       expires_at TIMESTAMPTZ NOT NULL,
+                       revoked_at TIMESTAMPTZ,
+                       replaced_by UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+                       created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE revoked_tokens (
+                       jti VARCHAR(64) PRIMARY KEY,
+                       user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+                       expires_at TIMESTAMPTZ NOT NULL,
+                       revoked_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE INDEX idx_cars_status ON cars(status);
+CREATE INDEX idx_cars_search ON cars USING GIN (search_vector);
+CREATE INDEX idx_cars_make_model_trgm ON cars USING GIN ((make || ' ' || model) gin_trgm_ops);
 CREATE INDEX idx_leads_phone ON leads(customer_phone);
+CREATE INDEX idx_leads_car ON leads(car_id);
+CREATE INDEX idx_leads_assigned ON leads(assigned_user_id, status);
+CREATE INDEX idx_lead_notes_lead ON lead_notes(lead_id, created_at);
+CREATE INDEX idx_lead_touches_lead ON lead_touches(lead_id, created_at);
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens(user_id);
+CREATE INDEX idx_car_status_events_car ON car_status_events(car_id, occurred_at);
+CREATE INDEX idx_exchange_rates_currency ON exchange_rates(currency, fetched_at DESC);
+CREATE UNIQUE INDEX idx_pricing_rules_scope ON pricing_rules(LOWER(COALESCE(make, '')), COALESCE(category, ''));
+CREATE INDEX idx_watchlist_car ON watchlist(car_id);
+CREATE INDEX idx_price_changes_car ON price_changes(car_id, changed_at DESC);
 
--- 6. Insert Mock Data (With Images)
+-- 9. Insert Mock Data (With Images)
 INSERT INTO users (username, password_hash, role)
 VALUES ('admin', '$2a$12$R9h/lSu6yokEiZfTrPhGueu7u.JOf.9v69/v8b6rPd.YyOnz9gE2.', 'admin');
 
+-- Catch-all rule matching the original flat conversion
+INSERT INTO pricing_rules (rounding_step_kzt) VALUES (100000);
+
 INSERT INTO cars (vin, make, model, price_usd, status, location, image_url)
 VALUES
 ('CAR-095', 'Lightning', 'McQueen', 950000, 'available', 'Radiator Springs', '/uploads/mcqueen.png'),